@@ -0,0 +1,61 @@
+package orderedmap
+
+// SumNumeric returns the sum of the numeric values in the map, as a
+// float64. Values that are not numbers, including nested maps and arrays,
+// are ignored. json.Numbers are included.
+func (o *OrderedMap) SumNumeric() float64 {
+	var sum float64
+	if o == nil {
+		return sum
+	}
+	for _, k := range o.keys {
+		if f, ok := toFloat64(o.values[k]); ok {
+			sum += f
+		}
+	}
+	return sum
+}
+
+// AggregateBy sums the numeric values of the map in groups named by group,
+// which is called with each key. The result maps each group to its sum, in
+// the order the groups first appear. Values that are not numbers are
+// ignored.
+func (o *OrderedMap) AggregateBy(group func(key string) string) OrderedMap {
+	result := o.subMap(func(string) bool { return false })
+	if o == nil {
+		return result
+	}
+	for _, k := range o.keys {
+		f, ok := toFloat64(o.values[k])
+		if !ok {
+			continue
+		}
+		g := result.canonicalKey(group(k))
+		sum, _ := result.values[g].(float64)
+		result.Set(g, sum+f)
+	}
+	return result
+}
+
+// MergeNumeric returns a map with the keys of o followed by the keys of
+// other that are not in o. Where both maps have a number for a key, the
+// value is op applied to the two numbers as float64s; otherwise it is the
+// value from o if the key is in o, or from other.
+func (o *OrderedMap) MergeNumeric(other *OrderedMap, op func(a, b float64) float64) OrderedMap {
+	result := o.subMap(func(string) bool { return true })
+	for _, k := range other.Keys() {
+		b := other.values[k]
+		k = result.canonicalKey(k)
+		a, exists := result.values[k]
+		if !exists {
+			result.Set(k, b)
+			continue
+		}
+		fa, okA := toFloat64(a)
+		fb, okB := toFloat64(b)
+		if okA && okB {
+			result.Set(k, op(fa, fb))
+		}
+	}
+	return result
+}
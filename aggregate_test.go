@@ -0,0 +1,63 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSumNumeric(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":"2","c":2.5,"d":{"e":10},"f":true}`)
+	o.Set("g", json.Number("0.5"))
+	o.Set("h", 3)
+	if sum := o.SumNumeric(); sum != 7 {
+		t.Error("SumNumeric", sum)
+	}
+}
+
+func TestAggregateBy(t *testing.T) {
+	o := mustUnmarshal(t, `{"eu.fr":1,"us.ny":2,"eu.de":3,"eu.note":"x","us.ca":4}`)
+	byRegion := o.AggregateBy(func(key string) string {
+		return strings.Split(key, ".")[0]
+	})
+	b, _ := json.Marshal(byRegion)
+	if string(b) != `{"eu":4,"us":6}` {
+		t.Error("AggregateBy", string(b))
+	}
+	// groups are named with the key transform applied
+	o = mustUnmarshal(t, `{"a":1,"b":2}`)
+	o.SetKeyTransform(strings.ToLower)
+	totals := o.AggregateBy(func(key string) string {
+		if key == "a" {
+			return "total"
+		}
+		return "Total"
+	})
+	b, _ = json.Marshal(totals)
+	if string(b) != `{"total":3}` {
+		t.Error("AggregateBy with key transform", string(b))
+	}
+}
+
+func TestMergeNumeric(t *testing.T) {
+	a := mustUnmarshal(t, `{"x":1,"y":"s","z":3}`)
+	b := mustUnmarshal(t, `{"w":5,"z":4,"y":2,"x":10}`)
+	sum := a.MergeNumeric(&b, func(a, b float64) float64 { return a + b })
+	out, _ := json.Marshal(sum)
+	if string(out) != `{"x":11,"y":"s","z":7,"w":5}` {
+		t.Error("MergeNumeric", string(out))
+	}
+	// a is unchanged
+	out, _ = json.Marshal(a)
+	if string(out) != `{"x":1,"y":"s","z":3}` {
+		t.Error("MergeNumeric modified its receiver", string(out))
+	}
+	// keys of other are matched with the key transform of o applied
+	a.SetKeyTransform(strings.ToLower)
+	b = mustUnmarshal(t, `{"X":10,"Z":4}`)
+	sum = a.MergeNumeric(&b, func(a, b float64) float64 { return a + b })
+	out, _ = json.Marshal(sum)
+	if string(out) != `{"x":11,"y":"s","z":7}` {
+		t.Error("MergeNumeric with key transform", string(out))
+	}
+}
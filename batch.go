@@ -0,0 +1,123 @@
+package orderedmap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// BatchDecoder decodes a stream of JSON objects, either newline delimited
+// (NDJSON) or the elements of a single JSON array, reusing maps, their key
+// slices and a scratch buffer across records to reduce allocations.
+//
+// Maps returned by Next belong to the decoder until they are passed to
+// Release, after which they may be reused for a later record. Nested maps
+// and slices are not reused.
+type BatchDecoder struct {
+	r       *bufio.Reader
+	dec     *json.Decoder
+	array   bool
+	started bool
+	raw     json.RawMessage
+	free    []*OrderedMap
+}
+
+// NewBatchDecoder returns a BatchDecoder reading from r.
+func NewBatchDecoder(r io.Reader) *BatchDecoder {
+	br := bufio.NewReader(r)
+	return &BatchDecoder{r: br, dec: json.NewDecoder(br)}
+}
+
+// Next decodes the next record. It returns io.EOF when there are no more
+// records.
+func (bd *BatchDecoder) Next() (*OrderedMap, error) {
+	if !bd.started {
+		if err := bd.start(); err != nil {
+			return nil, err
+		}
+	}
+	if bd.array && !bd.dec.More() {
+		if _, err := bd.dec.Token(); err != nil { // ']'
+			return nil, err
+		}
+		bd.array = false
+		return nil, io.EOF
+	}
+	if err := bd.dec.Decode(&bd.raw); err != nil {
+		return nil, err
+	}
+	o := bd.get()
+	if err := o.unmarshalJSON(bd.raw, o.keys, nil); err != nil {
+		bd.Release(o)
+		return nil, err
+	}
+	return o, nil
+}
+
+// Release returns o to the decoder for reuse. o must not be used after it
+// is released.
+func (bd *BatchDecoder) Release(o *OrderedMap) {
+	bd.free = append(bd.free, o)
+}
+
+// Each calls fn with each record in turn, releasing it when fn returns, so
+// fn must not retain the map. It stops at the first error from decoding or
+// from fn, and returns nil at the end of the stream.
+func (bd *BatchDecoder) Each(fn func(o *OrderedMap) error) error {
+	for {
+		o, err := bd.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = fn(o)
+		bd.Release(o)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// start detects whether the stream is an array and consumes its '['.
+func (bd *BatchDecoder) start() error {
+	bd.started = true
+	for {
+		c, err := bd.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		if err := bd.r.UnreadByte(); err != nil {
+			return err
+		}
+		if c != '[' {
+			return nil
+		}
+		if _, err := bd.dec.Token(); err != nil {
+			return err
+		}
+		bd.array = true
+		return nil
+	}
+}
+
+// get returns a released map cleared for reuse, or a new map.
+func (bd *BatchDecoder) get() *OrderedMap {
+	if len(bd.free) == 0 {
+		return New()
+	}
+	o := bd.free[len(bd.free)-1]
+	bd.free = bd.free[:len(bd.free)-1]
+	for k := range o.values {
+		delete(o.values, k)
+	}
+	o.keys = o.keys[:0]
+	o.nulls = nil
+	o.guard = nil
+	return o
+}
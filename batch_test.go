@@ -0,0 +1,71 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBatchDecoder(t *testing.T) {
+	inputs := []string{
+		"{\"b\":1,\"a\":2}\n{\"c\":[{\"z\":1,\"y\":2}]}\n\n{\"a\":3}\n",
+		` [ {"b":1,"a":2}, {"c":[{"z":1,"y":2}]}, {"a":3} ] `,
+	}
+	expected := []string{`{"b":1,"a":2}`, `{"c":[{"z":1,"y":2}]}`, `{"a":3}`}
+	for _, input := range inputs {
+		bd := NewBatchDecoder(strings.NewReader(input))
+		var got []string
+		var maps []*OrderedMap
+		err := bd.Each(func(o *OrderedMap) error {
+			b, err := json.Marshal(o)
+			got = append(got, string(b))
+			maps = append(maps, o)
+			return err
+		})
+		if err != nil {
+			t.Fatal("Each", err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Error("BatchDecoder", input, got)
+		}
+		// released maps are reused
+		if maps[0] != maps[1] || maps[1] != maps[2] {
+			t.Error("BatchDecoder did not reuse maps")
+		}
+	}
+
+	bd := NewBatchDecoder(strings.NewReader(`{"a":1} {"b":2}`))
+	first, err := bd.Next()
+	if err != nil {
+		t.Fatal("Next", err)
+	}
+	second, err := bd.Next()
+	if err != nil {
+		t.Fatal("Next", err)
+	}
+	if first == second || !reflect.DeepEqual(first.Keys(), []string{"a"}) {
+		t.Error("Next reused an unreleased map")
+	}
+	if _, err := bd.Next(); err != io.EOF {
+		t.Error("Next at end", err)
+	}
+
+	bd = NewBatchDecoder(strings.NewReader(`{"a":1} [1]`))
+	if err := bd.Each(func(*OrderedMap) error { return nil }); err == nil {
+		t.Error("Each with non-object record did not fail")
+	}
+}
+
+func BenchmarkBatchDecoder(b *testing.B) {
+	line := `{"id":1,"name":"x","tags":["a","b"],"score":1.5}` + "\n"
+	input := strings.Repeat(line, 1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bd := NewBatchDecoder(strings.NewReader(input))
+		if err := bd.Each(func(*OrderedMap) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// SetBigNumbers sets whether UnmarshalJSON decodes numbers that cannot be
+// represented exactly as a float64 into math/big types: *big.Int for
+// integers and *big.Float for other numbers, with enough precision for all
+// of their digits. Other numbers are decoded as float64 as usual. While the
+// setting is on both big types are marshalled as JSON numbers, and an
+// infinite *big.Float is an error. The setting is inherited by nested
+// maps.
+func (o *OrderedMap) SetBigNumbers(on bool) {
+	o.bigNumbers = on
+}
+
+// unmarshalUseNumber is json.Unmarshal with numbers decoded as json.Number.
+func unmarshalUseNumber(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("orderedmap: invalid data after top-level value")
+	}
+	return nil
+}
+
+// convertNumbers replaces the json.Numbers in v with a float64 or a big
+// number.
+func convertNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		return bigNumber(v)
+	case OrderedMap:
+		for k, elem := range v.values {
+			v.values[k] = convertNumbers(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = convertNumbers(elem)
+		}
+	}
+	return v
+}
+
+// bigNumber returns n as a float64 if that is exact, otherwise as a
+// *big.Int or *big.Float.
+func bigNumber(n json.Number) interface{} {
+	s := string(n)
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil && sameDecimal(s, strconv.FormatFloat(f, 'g', -1, 64)) {
+		return f
+	}
+	if !strings.ContainsAny(s, ".eE") {
+		if i, ok := new(big.Int).SetString(s, 10); ok {
+			return i
+		}
+	}
+	// about 3.33 bits per decimal digit
+	prec := uint(len(s))*4 + 64
+	bf, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return f
+	}
+	return bf
+}
+
+// sameDecimal reports whether the decimal numbers a and b have the same
+// value, by comparing their significant digits and exponents.
+func sameDecimal(a, b string) bool {
+	negA, digitsA, expA, okA := decimalParts(a)
+	negB, digitsB, expB, okB := decimalParts(b)
+	if !okA || !okB || digitsA != digitsB {
+		return false
+	}
+	// zero has no digits, and -0 equals 0
+	return digitsA == "" || (negA == negB && expA == expB)
+}
+
+// decimalParts splits a decimal number into its sign, its significant
+// digits without leading or trailing zeros, and the exponent exp such that
+// its value is 0.digits * 10^exp.
+func decimalParts(s string) (neg bool, digits string, exp int, ok bool) {
+	if strings.HasPrefix(s, "-") {
+		neg, s = true, s[1:]
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return false, "", 0, false
+		}
+		exp, s = e, s[:i]
+	}
+	digits = s
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		exp += i
+	} else {
+		exp += len(s)
+	}
+	trimmed := strings.TrimLeft(digits, "0")
+	exp -= len(digits) - len(trimmed)
+	return neg, strings.TrimRight(trimmed, "0"), exp, true
+}
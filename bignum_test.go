@@ -0,0 +1,103 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestSetBigNumbers(t *testing.T) {
+	input := `{"small":1,"frac":0.1,"exp":1.5e3,"int":123456789012345678901234567890,"neg":-9007199254740993,"dec":3.14159265358979323846264338327950288,"nested":{"a":[12345678901234567890123]}}`
+	o := New()
+	o.SetBigNumbers(true)
+	if err := json.Unmarshal([]byte(input), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	for _, key := range []string{"small", "frac", "exp"} {
+		if _, ok := o.values[key].(float64); !ok {
+			t.Errorf("SetBigNumbers %s is %T, not float64", key, o.values[key])
+		}
+	}
+	for _, key := range []string{"int", "neg"} {
+		if _, ok := o.values[key].(*big.Int); !ok {
+			t.Errorf("SetBigNumbers %s is %T, not *big.Int", key, o.values[key])
+		}
+	}
+	if i := o.values["int"].(*big.Int); i.String() != "123456789012345678901234567890" {
+		t.Error("SetBigNumbers int", i)
+	}
+	if _, ok := o.values["dec"].(*big.Float); !ok {
+		t.Errorf("SetBigNumbers dec is %T, not *big.Float", o.values["dec"])
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	expected := `{"small":1,"frac":0.1,"exp":1500,"int":123456789012345678901234567890,"neg":-9007199254740993,"dec":3.14159265358979323846264338327950288,"nested":{"a":[12345678901234567890123]}}`
+	if string(b) != expected {
+		t.Error("SetBigNumbers marshal", string(b))
+	}
+	if err := o.UnmarshalJSON([]byte(`{"a":1} x`)); err == nil {
+		t.Error("SetBigNumbers with trailing data did not fail")
+	}
+}
+
+func TestBigFloatMarshal(t *testing.T) {
+	o := New()
+	o.Set("f", big.NewFloat(1.5))
+	b, err := json.Marshal(o)
+	if err != nil || string(b) != `{"f":"1.5"}` {
+		t.Error("big.Float without SetBigNumbers", string(b), err)
+	}
+	o.SetBigNumbers(true)
+	b, err = json.Marshal(o)
+	if err != nil || string(b) != `{"f":1.5}` {
+		t.Error("big.Float with SetBigNumbers", string(b), err)
+	}
+	o.Set("f", new(big.Float).SetInf(false))
+	if b, err := json.Marshal(o); err == nil {
+		t.Error("big.Float infinity did not fail", string(b))
+	}
+	o.SetUnsupportedMode(UnsupportedSkip)
+	if b, err := json.Marshal(o); err != nil || string(b) != `{}` {
+		t.Error("big.Float infinity skipped", string(b), err)
+	}
+}
+
+func TestSameDecimal(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"1", "1", true},
+		{"1.0", "1", true},
+		{"100", "1e2", true},
+		{"0.00120", "1.2e-3", true},
+		{"-0", "0", true},
+		{"-1", "1", false},
+		{"9007199254740993", "9007199254740992", false},
+		{"1e5", "1e+05", true},
+	}
+	for _, test := range tests {
+		if sameDecimal(test.a, test.b) != test.equal {
+			t.Error("sameDecimal", test.a, test.b)
+		}
+	}
+}
+
+func TestBigNumberAccessors(t *testing.T) {
+	o := New()
+	o.SetBigNumbers(true)
+	if err := json.Unmarshal([]byte(`{"fits":-9007199254740993,"big":123456789012345678901234567890}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if i, ok := o.GetInt64("fits"); !ok || i != -9007199254740993 {
+		t.Error("GetInt64 big.Int", i, ok)
+	}
+	if _, ok := o.GetInt64("big"); ok {
+		t.Error("GetInt64 out of range big.Int")
+	}
+	if f, ok := o.GetFloat64("big"); !ok || f != 1.2345678901234568e29 {
+		t.Error("GetFloat64 big.Int", f, ok)
+	}
+}
@@ -0,0 +1,189 @@
+// Package boltstorage provides an orderedmap.Storage backed by a bbolt
+// bucket, so that ordered documents larger than memory can be edited with
+// orderedmap.StoredMap.
+package boltstorage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/iancoleman/orderedmap"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	valuesBucket = []byte("values")
+	indexBucket  = []byte("index")
+	orderBucket  = []byte("order")
+)
+
+// keyPrefix is written before every map key, as bbolt does not accept the
+// empty key, which is a valid JSON key.
+const keyPrefix = "k"
+
+// boltKey returns the form of key stored in the buckets.
+func boltKey(key string) []byte {
+	return []byte(keyPrefix + key)
+}
+
+// Storage stores the entries of an ordered map in a bbolt bucket. The bucket
+// holds three nested buckets: values maps each key to its JSON encoding,
+// order maps an increasing sequence number to each key, and index maps each
+// key to its sequence number. Keys are stored with a one byte prefix. They
+// are iterated in sequence order, which is the order they were first
+// stored in.
+type Storage struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+// New returns a Storage over the bucket of db with the given name, creating
+// it if needed.
+func New(db *bolt.DB, bucket string) (*Storage, error) {
+	s := &Storage{db: db, bucket: []byte(bucket)}
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+		for _, name := range [][]byte{valuesBucket, indexBucket, orderBucket} {
+			if _, err := b.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// buckets returns the nested buckets of s in tx.
+func (s *Storage) buckets(tx *bolt.Tx) (values, index, order *bolt.Bucket, err error) {
+	b := tx.Bucket(s.bucket)
+	if b == nil {
+		return nil, nil, nil, errors.New("boltstorage: bucket not found")
+	}
+	return b.Bucket(valuesBucket), b.Bucket(indexBucket), b.Bucket(orderBucket), nil
+}
+
+// Load returns the value of key, decoded with orderedmap so that nested
+// objects keep their order.
+func (s *Storage) Load(key string) (value interface{}, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		values, _, _, err := s.buckets(tx)
+		if err != nil {
+			return err
+		}
+		b := values.Get(boltKey(key))
+		if b == nil {
+			return nil
+		}
+		ok = true
+		value, err = decode(b)
+		return err
+	})
+	return value, ok, err
+}
+
+// Store sets the value of key, which is stored as JSON.
+func (s *Storage) Store(key string, value interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	k := boltKey(key)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		values, index, order, err := s.buckets(tx)
+		if err != nil {
+			return err
+		}
+		if index.Get(k) == nil {
+			n, err := order.NextSequence()
+			if err != nil {
+				return err
+			}
+			seq := make([]byte, 8)
+			binary.BigEndian.PutUint64(seq, n)
+			if err := order.Put(seq, k); err != nil {
+				return err
+			}
+			if err := index.Put(k, seq); err != nil {
+				return err
+			}
+		}
+		return values.Put(k, b)
+	})
+}
+
+// Remove deletes key.
+func (s *Storage) Remove(key string) error {
+	k := boltKey(key)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		values, index, order, err := s.buckets(tx)
+		if err != nil {
+			return err
+		}
+		seq := index.Get(k)
+		if seq == nil {
+			return nil
+		}
+		if err := order.Delete(seq); err != nil {
+			return err
+		}
+		if err := index.Delete(k); err != nil {
+			return err
+		}
+		return values.Delete(k)
+	})
+}
+
+// Range calls fn for each key and value in order until fn returns false.
+// It runs in a read-only transaction, so fn must not modify the storage.
+func (s *Storage) Range(fn func(key string, value interface{}) bool) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		values, _, order, err := s.buckets(tx)
+		if err != nil {
+			return err
+		}
+		c := order.Cursor()
+		for _, key := c.First(); key != nil; _, key = c.Next() {
+			value, err := decode(values.Get(key))
+			if err != nil {
+				return err
+			}
+			if !fn(string(key[len(keyPrefix):]), value) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// Len returns the number of keys.
+func (s *Storage) Len() (n int, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		_, index, _, err := s.buckets(tx)
+		if err != nil {
+			return err
+		}
+		n = index.Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+// decode decodes a stored JSON value, using orderedmap for objects.
+func decode(b []byte) (interface{}, error) {
+	o := orderedmap.New()
+	wrapped := append(append([]byte(`{"v":`), b...), '}')
+	if err := o.UnmarshalJSON(wrapped); err != nil {
+		return nil, err
+	}
+	v, _ := o.Get("v")
+	return v, nil
+}
+
+var _ orderedmap.Storage = (*Storage)(nil)
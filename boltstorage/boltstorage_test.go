@@ -0,0 +1,85 @@
+package boltstorage
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/iancoleman/orderedmap"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStorage(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "test.db"), 0600, nil)
+	if err != nil {
+		t.Fatal("Open", err)
+	}
+	defer db.Close()
+	s, err := New(db, "doc")
+	if err != nil {
+		t.Fatal("New", err)
+	}
+	m := orderedmap.NewStoredMap(s)
+	nested := orderedmap.Of("z", 1, "y", 2)
+	for _, kv := range []struct {
+		key   string
+		value interface{}
+	}{{"b", 1}, {"a", "x"}, {"c", nested}, {"b", 3}} {
+		if err := m.Set(kv.key, kv.value); err != nil {
+			t.Fatal("Set", err)
+		}
+	}
+	if err := m.Delete("a"); err != nil {
+		t.Fatal("Delete", err)
+	}
+	if err := m.Set("a", "x"); err != nil {
+		t.Fatal("Set", err)
+	}
+	if keys, err := m.Keys(); err != nil || !reflect.DeepEqual(keys, []string{"b", "c", "a"}) {
+		t.Error("Keys", keys, err)
+	}
+	if n, err := m.Len(); err != nil || n != 3 {
+		t.Error("Len", n, err)
+	}
+	v, ok, err := m.Get("c")
+	if err != nil || !ok {
+		t.Fatal("Get", ok, err)
+	}
+	if c, ok := v.(orderedmap.OrderedMap); !ok || !reflect.DeepEqual(c.Keys(), []string{"z", "y"}) {
+		t.Error("Get nested map", v)
+	}
+	if _, ok, _ := m.Get("missing"); ok {
+		t.Error("Get missing key")
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"b":3,"c":{"z":1,"y":2},"a":"x"}` {
+		t.Error("Marshal", string(b))
+	}
+
+	// the empty key is a valid JSON key
+	if err := m.Set("", 4); err != nil {
+		t.Fatal("Set empty key", err)
+	}
+	if v, ok, err := m.Get(""); err != nil || !ok || v != float64(4) {
+		t.Error("Get empty key", v, ok, err)
+	}
+	if b, _ := json.Marshal(m); string(b) != `{"b":3,"c":{"z":1,"y":2},"a":"x","":4}` {
+		t.Error("Marshal with empty key", string(b))
+	}
+	if err := m.Delete(""); err != nil {
+		t.Fatal("Delete empty key", err)
+	}
+
+	// the order survives reopening the storage
+	s, err = New(db, "doc")
+	if err != nil {
+		t.Fatal("New", err)
+	}
+	if keys, _ := orderedmap.NewStoredMap(s).Keys(); !reflect.DeepEqual(keys, []string{"b", "c", "a"}) {
+		t.Error("Keys after reopening", keys)
+	}
+}
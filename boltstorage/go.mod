@@ -0,0 +1,16 @@
+module github.com/iancoleman/orderedmap/boltstorage
+
+go 1.21
+
+require (
+	github.com/iancoleman/orderedmap v0.3.0
+	go.etcd.io/bbolt v1.3.10
+)
+
+require golang.org/x/sys v0.10.0 // indirect
+
+// Storage is newer than v0.3.0, so within this repository boltstorage is
+// built against the orderedmap beside it. Raise the requirement above to
+// the first release with Storage when it is tagged, as replace is ignored
+// when boltstorage is used as a dependency.
+replace github.com/iancoleman/orderedmap => ../
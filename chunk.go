@@ -0,0 +1,106 @@
+package orderedmap
+
+import (
+	"bytes"
+	"io"
+)
+
+// ChunkEncoder writes a map as JSON in chunks of bounded size, so that a
+// server can stream a very large map with flow control between chunks
+// instead of buffering the whole document. Chunks always end between
+// entries of the map: each chunk holds at least one entry, and entries are
+// added to it until it reaches the chunk size, so a chunk exceeds the size
+// by at most one entry.
+//
+// The output is the same as MarshalJSON with FormatV1, escaping HTML and
+// applying the escape profile, time layout, stringer, number formatter and
+// unsupported value settings of the map. The map must not be modified until
+// encoding is finished.
+type ChunkEncoder struct {
+	o       *OrderedMap
+	size    int
+	next    int
+	written int
+	started bool
+	done    bool
+	buf     bytes.Buffer
+}
+
+// NewChunkEncoder returns a ChunkEncoder for the map with chunks of about
+// size bytes.
+func (o *OrderedMap) NewChunkEncoder(size int) *ChunkEncoder {
+	return &ChunkEncoder{o: o, size: size}
+}
+
+// Next returns the next chunk, or io.EOF when the whole map has been
+// returned. The chunk is only valid until the next call to Next.
+func (c *ChunkEncoder) Next() ([]byte, error) {
+	if c.done {
+		return nil, io.EOF
+	}
+	c.buf.Reset()
+	if !c.started {
+		c.buf.WriteByte('{')
+		c.started = true
+	}
+	e := newEncodeState(&c.buf, false)
+	e.useSettings(c.o)
+	e.escapeHTML = c.o.escapeHTML
+	e.fixedEscape = c.o.escapeHTMLRecursive
+	for added := 0; c.next < len(c.o.keys) && (added == 0 || c.buf.Len() < c.size); added++ {
+		start := c.buf.Len()
+		if c.written > 0 {
+			c.buf.WriteByte(',')
+		}
+		k := c.o.keys[c.next]
+		c.next++
+		if err := e.encode(k); err != nil {
+			return nil, err
+		}
+		c.buf.WriteByte(':')
+		if err := e.marshalElem(k, c.o.values[k]); err == errSkipValue {
+			c.buf.Truncate(start)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		c.written++
+		if c.o.escapeProfile != EscapeDefault {
+			escaped, err := applyEscapeProfile(c.buf.Bytes()[start:], c.o.escapeProfile)
+			if err != nil {
+				return nil, err
+			}
+			c.buf.Truncate(start)
+			c.buf.Write(escaped)
+		}
+	}
+	if c.next == len(c.o.keys) {
+		c.buf.WriteByte('}')
+		c.done = true
+	}
+	return c.buf.Bytes(), nil
+}
+
+// WriteTo writes the remaining chunks to w, flushing w after each chunk if
+// it has a Flush method, as http.ResponseWriter does.
+func (c *ChunkEncoder) WriteTo(w io.Writer) (int64, error) {
+	flusher, _ := w.(interface{ Flush() })
+	var written int64
+	for {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package orderedmap
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type flushRecorder struct {
+	bytes.Buffer
+	chunks []string
+}
+
+func (f *flushRecorder) Flush() {
+	f.chunks = append(f.chunks, f.String())
+	f.Reset()
+}
+
+func TestChunkEncoder(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":"<1>","b":{"c":[1,2,3]},"d":"0123456789","e":null}`)
+	expected, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal("MarshalJSON", err)
+	}
+	for _, size := range []int{1, 10, 20, 1000} {
+		c := o.NewChunkEncoder(size)
+		var chunks []string
+		for {
+			chunk, err := c.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal("Next", err)
+			}
+			chunks = append(chunks, string(chunk))
+		}
+		if got := strings.Join(chunks, ""); got != string(expected) {
+			t.Error("ChunkEncoder", size, got)
+		}
+		if size == 1 && len(chunks) != 4 {
+			t.Error("ChunkEncoder chunks", chunks)
+		}
+		if size == 1000 && len(chunks) != 1 {
+			t.Error("ChunkEncoder chunks", chunks)
+		}
+	}
+
+	// numbers are formatted with their path, as by MarshalJSON
+	f := mustUnmarshal(t, `{"price":1.5,"items":[{"price":2}],"qty":3}`)
+	f.SetNumberFormatter(func(path []string, n interface{}) string {
+		if path[len(path)-1] == "price" {
+			return fmt.Sprintf("%.2f", n)
+		}
+		return ""
+	})
+	formatted, _ := f.MarshalJSON()
+	var chunks []string
+	for c := f.NewChunkEncoder(1); ; {
+		chunk, err := c.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("Next with number formatter", err)
+		}
+		chunks = append(chunks, string(chunk))
+	}
+	if got := strings.Join(chunks, ""); got != string(formatted) || got != `{"price":1.50,"items":[{"price":2.00}],"qty":3}` {
+		t.Error("ChunkEncoder with number formatter", got, string(formatted))
+	}
+
+	var w flushRecorder
+	n, err := o.NewChunkEncoder(20).WriteTo(&w)
+	if err != nil || n != int64(len(expected)) {
+		t.Error("WriteTo", n, err)
+	}
+	expectedChunks := []string{`{"a":"\u003c1\u003e"`, `,"b":{"c":[1,2,3]},"d":"0123456789"`, `,"e":null}`}
+	if strings.Join(w.chunks, "|") != strings.Join(expectedChunks, "|") {
+		t.Error("WriteTo chunks", w.chunks)
+	}
+
+	empty := New()
+	chunk, err := empty.NewChunkEncoder(10).Next()
+	if string(chunk) != "{}" || err != nil {
+		t.Error("ChunkEncoder of empty map", string(chunk), err)
+	}
+	o.SetEscapeProfile(EscapeASCII)
+	o.Set("a", "é")
+	chunk, _ = o.NewChunkEncoder(1).Next()
+	if string(chunk) != `{"a":"\u00e9"` {
+		t.Error("ChunkEncoder escape profile", string(chunk))
+	}
+}
@@ -0,0 +1,251 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxMismatches is the number of mismatches StrictOrderEqual reports.
+const maxMismatches = 10
+
+// Mismatch describes a difference between two JSON documents.
+type Mismatch struct {
+	// Path is the JSON Pointer of the differing value.
+	Path string
+	// Reason describes the difference.
+	Reason string
+	// A and B are the differing values, or the differing keys for a key
+	// order mismatch.
+	A, B interface{}
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: %s: %v != %v", m.Path, m.Reason, m.A, m.B)
+}
+
+// StrictOrderEqual reports whether the JSON documents a and b have the same
+// content with the same key order in every object. If not, it returns up to
+// the first 10 mismatches in document order. Invalid JSON is reported as a
+// mismatch at the root.
+func StrictOrderEqual(a, b []byte) (bool, []Mismatch) {
+	va, errA := unmarshalValue(a)
+	vb, errB := unmarshalValue(b)
+	if errA != nil || errB != nil {
+		return false, []Mismatch{{Path: "", Reason: "invalid json", A: errA, B: errB}}
+	}
+	c := comparison{ordered: true}
+	c.compare("", va, vb)
+	return len(c.mismatches) == 0, c.mismatches
+}
+
+// Equal reports whether o and other have the same keys in the same order
+// with equal values. Nested maps are compared in the same way, whether
+// stored as OrderedMap or *OrderedMap, except that plain Go maps have no
+// order to compare. Arrays are equal if their elements are, whatever their
+// slice type, and numbers are equal if their values are, whatever their
+// type, so a map built with Set can equal one decoded from JSON.
+func (o *OrderedMap) Equal(other OrderedMap) bool {
+	return valuesEqual(o, other, true)
+}
+
+// EqualUnordered reports whether o and other are equal as Equal does, but
+// ignoring the order of keys in o, other and their nested maps.
+func (o *OrderedMap) EqualUnordered(other OrderedMap) bool {
+	return valuesEqual(o, other, false)
+}
+
+// unmarshalValue decodes any JSON value, using OrderedMap for objects.
+func unmarshalValue(b []byte) (interface{}, error) {
+	// b is wrapped in an object below, so check that it is a single value
+	if !json.Valid(b) {
+		return nil, errors.New("orderedmap: invalid json value")
+	}
+	o := New()
+	wrapped := append(append([]byte(`{"":`), b...), '}')
+	if err := o.UnmarshalJSON(wrapped); err != nil {
+		return nil, err
+	}
+	if len(o.keys) != 1 {
+		return nil, errors.New("orderedmap: invalid json value")
+	}
+	v, _ := o.Get("")
+	return v, nil
+}
+
+// derefMap returns the OrderedMap v points to if v is a non-nil
+// *OrderedMap, otherwise v.
+func derefMap(v interface{}) interface{} {
+	if m, ok := v.(*OrderedMap); ok && m != nil {
+		return *m
+	}
+	return v
+}
+
+// valuesEqual reports whether a and b are deeply equal, optionally
+// requiring the keys of maps to be in the same order.
+func valuesEqual(a, b interface{}, ordered bool) bool {
+	c := comparison{ordered: ordered}
+	c.compare("", a, b)
+	return len(c.mismatches) == 0
+}
+
+// pointerEscape escapes a key for use in a JSON Pointer.
+func pointerEscape(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
+
+type comparison struct {
+	ordered    bool
+	mismatches []Mismatch
+}
+
+func (c *comparison) add(path, reason string, a, b interface{}) {
+	if len(c.mismatches) < maxMismatches {
+		c.mismatches = append(c.mismatches, Mismatch{path, reason, a, b})
+	}
+}
+
+func (c *comparison) compare(path string, a, b interface{}) {
+	if len(c.mismatches) >= maxMismatches {
+		return
+	}
+	// plain maps have no order to compare
+	_, aPlain := a.(map[string]interface{})
+	_, bPlain := b.(map[string]interface{})
+	a, b = normalizeValue(a), normalizeValue(b)
+	switch a := a.(type) {
+	case OrderedMap:
+		b, ok := b.(OrderedMap)
+		if !ok {
+			c.add(path, "type", a, b)
+			return
+		}
+		if c.ordered && !aPlain && !bPlain {
+			for i := 0; i < len(a.keys) && i < len(b.keys); i++ {
+				if a.keys[i] != b.keys[i] {
+					c.add(path, "key order", a.keys[i], b.keys[i])
+					break
+				}
+			}
+		}
+		for _, k := range a.keys {
+			bv, ok := b.values[k]
+			if !ok {
+				c.add(path+"/"+pointerEscape(k), "missing in b", a.values[k], nil)
+				continue
+			}
+			c.compare(path+"/"+pointerEscape(k), a.values[k], bv)
+		}
+		for _, k := range b.keys {
+			if _, ok := a.values[k]; !ok {
+				c.add(path+"/"+pointerEscape(k), "missing in a", nil, b.values[k])
+			}
+		}
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			c.add(path, "type", a, b)
+			return
+		}
+		if len(a) != len(b) {
+			c.add(path, "array length", len(a), len(b))
+		}
+		for i := 0; i < len(a) && i < len(b); i++ {
+			c.compare(fmt.Sprintf("%s/%d", path, i), a[i], b[i])
+		}
+	default:
+		if reflect.DeepEqual(a, b) {
+			return
+		}
+		// numbers of different types are equal if their values are
+		if !numbersEqual(a, b) {
+			c.add(path, "value", a, b)
+		}
+	}
+}
+
+// numbersEqual reports whether a and b are numbers with the same value.
+// Floats are compared as float64, and other numbers exactly.
+func numbersEqual(a, b interface{}) bool {
+	if isFloat(a) && isFloat(b) {
+		af, _ := toFloat64(a)
+		bf, _ := toFloat64(b)
+		return af == bf
+	}
+	ar, aNum := exactNumber(a)
+	br, bNum := exactNumber(b)
+	return aNum && bNum && ar.Cmp(br) == 0
+}
+
+func isFloat(v interface{}) bool {
+	k := reflect.ValueOf(v).Kind()
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+// exactNumber returns the value of the number v as a big.Rat. It returns
+// false if v is not a finite number.
+func exactNumber(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case *big.Int:
+		if n != nil {
+			return new(big.Rat).SetInt(n), true
+		}
+		return nil, false
+	case *big.Float:
+		if n != nil && !n.IsInf() {
+			r, _ := n.Rat(nil)
+			return r, true
+		}
+		return nil, false
+	case json.Number:
+		return new(big.Rat).SetString(string(n))
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Rat).SetFrac(new(big.Int).SetUint64(rv.Uint()), big.NewInt(1)), true
+	case reflect.Float32, reflect.Float64:
+		if r := new(big.Rat).SetFloat64(rv.Float()); r != nil {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// normalizeValue returns v as an OrderedMap if it is a map, including a
+// plain map with its keys sorted, as a []interface{} if it is a slice
+// other than a []byte, or otherwise as it is.
+func normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case OrderedMap, []interface{}, []byte, nil:
+		return v
+	case *OrderedMap:
+		return derefMap(v)
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return OrderedMap{keys: keys, values: v}
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.IsNil() {
+		return v
+	}
+	s := make([]interface{}, rv.Len())
+	for i := range s {
+		s[i] = rv.Index(i).Interface()
+	}
+	return s
+}
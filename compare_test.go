@@ -0,0 +1,139 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestStrictOrderEqual(t *testing.T) {
+	equal, mismatches := StrictOrderEqual(
+		[]byte(`{"a":1,"b":{"x":[1,{"y":2}]}}`),
+		[]byte(` { "a" : 1, "b": {"x": [1, {"y": 2}]} } `),
+	)
+	if !equal || len(mismatches) != 0 {
+		t.Error("Equal documents reported as different", mismatches)
+	}
+	equal, mismatches = StrictOrderEqual(
+		[]byte(`{"a":1,"b":2,"c":{"a/b":[1,2],"z":"x"},"d":true}`),
+		[]byte(`{"b":2,"a":1,"c":{"a/b":[1],"z":"y"},"e":true}`),
+	)
+	if equal {
+		t.Fatal("Different documents reported as equal")
+	}
+	expected := []Mismatch{
+		{"", "key order", "a", "b"},
+		{"/c/a~1b", "array length", 2, 1},
+		{"/c/z", "value", "x", "y"},
+		{"/d", "missing in b", true, nil},
+		{"/e", "missing in a", nil, true},
+	}
+	if len(mismatches) != len(expected) {
+		t.Fatal("Mismatch count", len(mismatches), mismatches)
+	}
+	for i, m := range mismatches {
+		if m != expected[i] {
+			t.Error("Mismatch", i, m, "!=", expected[i])
+		}
+	}
+	// the number of mismatches is limited
+	_, mismatches = StrictOrderEqual(
+		[]byte(`[1,2,3,4,5,6,7,8,9,10,11,12]`),
+		[]byte(`[0,0,0,0,0,0,0,0,0,0,0,0]`),
+	)
+	if len(mismatches) != maxMismatches {
+		t.Error("Mismatch limit", len(mismatches))
+	}
+	if equal, _ := StrictOrderEqual([]byte(`{`), []byte(`{}`)); equal {
+		t.Error("Invalid json reported as equal")
+	}
+	// input that only forms a valid object once wrapped
+	for _, input := range []string{`1,"":2`, `1}{"":2`, ``} {
+		if equal, mismatches := StrictOrderEqual([]byte(input), []byte(`2`)); equal || len(mismatches) != 1 {
+			t.Error("Invalid json reported as equal", input, mismatches)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	decoded := mustUnmarshal(t, `{"a":1,"b":[{"c":true},"x"],"m":{"y":1,"x":2}}`)
+	built := New()
+	built.Set("a", 1)
+	inner := New()
+	inner.Set("c", true)
+	built.Set("b", []interface{}{inner, "x"})
+	built.Set("m", map[string]interface{}{"x": 2, "y": int64(1)})
+	if !decoded.Equal(*built) || !built.Equal(decoded) {
+		t.Error("Equal with mixed types")
+	}
+	typed := mustUnmarshal(t, `{"a":1,"b":[{"c":true},"x"],"m":{"y":1,"x":2}}`)
+	typed.Set("b", []OrderedMap{*inner})
+	if decoded.Equal(typed) {
+		t.Error("Equal with different arrays")
+	}
+	typed.Set("b", []interface{}{inner, "x"})
+	typed.Set("list", []string{"p"})
+	decoded.Set("list", []interface{}{"p"})
+	if !decoded.Equal(typed) {
+		t.Error("Equal with typed slice")
+	}
+	tests := []string{
+		`{"b":[{"c":true},"x"],"a":1,"m":{"y":1,"x":2},"list":["p"]}`,
+		`{"a":1,"b":[{"c":true},"x"],"m":{"x":2,"y":1},"list":["p"]}`,
+		`{"a":1,"b":[{"c":true},"x"],"m":{"y":1,"x":2},"list":["p"],"z":0}`,
+		`{"a":"1","b":[{"c":true},"x"],"m":{"y":1,"x":2},"list":["p"]}`,
+		`{"a":1,"b":[{"c":false},"x"],"m":{"y":1,"x":2},"list":["p"]}`,
+	}
+	for _, s := range tests {
+		if other := mustUnmarshal(t, s); decoded.Equal(other) {
+			t.Error("Equal", s)
+		}
+	}
+}
+
+func TestEqualNumbers(t *testing.T) {
+	bigA, _ := new(big.Int).SetString("1234567890123456789012345678901234567890", 10)
+	bigB, _ := new(big.Int).SetString("1234567890123456789012345678901234567891", 10)
+	tests := []struct {
+		a, b  interface{}
+		equal bool
+	}{
+		{1, float64(1), true},
+		{uint8(2), int64(2), true},
+		{json.Number("1.50"), 1.5, true},
+		{json.Number("1e2"), big.NewInt(100), true},
+		{big.NewFloat(0.5), float32(0.5), true},
+		{float32(0.1), 0.1, false},
+		{bigA, bigB, false},
+		{bigA, new(big.Int).Set(bigA), true},
+		{int64(1<<53 + 1), float64(1 << 53), false},
+		{json.Number("9007199254740993"), int64(1 << 53), false},
+		{uint64(1<<64 - 1), float64(1 << 64), false},
+		{1, "1", false},
+	}
+	for _, test := range tests {
+		a, b := New(), New()
+		a.Set("n", test.a)
+		b.Set("n", test.b)
+		if a.Equal(*b) != test.equal {
+			t.Error("Equal numbers", test.a, test.b)
+		}
+	}
+}
+
+func TestEqualUnordered(t *testing.T) {
+	a := mustUnmarshal(t, `{"a":1,"m":{"y":[{"q":1,"p":2}],"x":2}}`)
+	b := mustUnmarshal(t, `{"m":{"x":2,"y":[{"p":2,"q":1}]},"a":1}`)
+	if a.Equal(b) {
+		t.Error("Equal ignored order")
+	}
+	if !a.EqualUnordered(b) || !b.EqualUnordered(a) {
+		t.Error("EqualUnordered")
+	}
+	// arrays are still ordered
+	c := mustUnmarshal(t, `{"a":1,"m":{"y":[{"q":1,"p":2}],"x":2},"z":[1,2]}`)
+	d := mustUnmarshal(t, `{"a":1,"m":{"y":[{"q":1,"p":2}],"x":2},"z":[2,1]}`)
+	if c.EqualUnordered(d) || a.EqualUnordered(c) {
+		t.Error("EqualUnordered with different values")
+	}
+}
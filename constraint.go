@@ -0,0 +1,87 @@
+package orderedmap
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// A Constraint checks a value in a map, returning an error describing why
+// the value is not valid.
+type Constraint func(v interface{}) error
+
+// Violation is a value that does not satisfy a constraint.
+type Violation struct {
+	// Path is the path of the value, as given to Constrain.
+	Path string
+	// Err is the error returned by the constraint.
+	Err error
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("orderedmap: %s: %v", v.Path, v.Err)
+}
+
+type pathConstraint struct {
+	path        string
+	constraints []Constraint
+}
+
+// Constrain adds constraints on the value at path, which uses the syntax of
+// GetByPath, to be evaluated by Check. Maps built from some of the keys of
+// o, such as those returned by Pick, do not keep its constraints.
+func (o *OrderedMap) Constrain(path string, constraints ...Constraint) {
+	o.constraints = append(o.constraints, pathConstraint{path, constraints})
+}
+
+// Check evaluates the constraints added by Constrain and returns a violation
+// for each constraint that is not satisfied, in the order the constraints
+// were added. Paths that are not in the map are not checked.
+func (o *OrderedMap) Check() []Violation {
+	if o == nil {
+		return nil
+	}
+	var violations []Violation
+	for _, pc := range o.constraints {
+		v, ok := o.GetByPath(pc.path)
+		if !ok {
+			continue
+		}
+		for _, c := range pc.constraints {
+			if err := c(v); err != nil {
+				violations = append(violations, Violation{pc.path, err})
+			}
+		}
+	}
+	return violations
+}
+
+// Range returns a constraint requiring a number between min and max
+// inclusive.
+func Range(min, max float64) Constraint {
+	return func(v interface{}) error {
+		f, ok := toFloat64(v)
+		if !ok {
+			return fmt.Errorf("%v is not a number", v)
+		}
+		if f < min || f > max {
+			return fmt.Errorf("%v is not between %v and %v", v, min, max)
+		}
+		return nil
+	}
+}
+
+// Regexp returns a constraint requiring a string matching the regular
+// expression pattern. It panics if pattern does not compile.
+func Regexp(pattern string) Constraint {
+	re := regexp.MustCompile(pattern)
+	return func(v interface{}) error {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%v is not a string", v)
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("%q does not match %s", s, pattern)
+		}
+		return nil
+	}
+}
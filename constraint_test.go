@@ -0,0 +1,38 @@
+package orderedmap
+
+import (
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	o := mustUnmarshal(t, `{"name":"web-1","port":80,"backends":[{"port":70000},{"port":"x"}]}`)
+	o.Constrain("name", Regexp(`^[a-z]+-\d+$`))
+	o.Constrain("port", Range(1, 65535))
+	o.Constrain("missing", Range(1, 2))
+	if violations := o.Check(); len(violations) != 0 {
+		t.Error("Check", violations)
+	}
+	o.Set("name", "Web")
+	o.Constrain("backends.0.port", Range(1, 65535))
+	o.Constrain("backends.1.port", Range(1, 65535), Regexp(`^\d+$`))
+	violations := o.Check()
+	expected := []string{
+		`orderedmap: name: "Web" does not match ^[a-z]+-\d+$`,
+		`orderedmap: backends.0.port: 70000 is not between 1 and 65535`,
+		`orderedmap: backends.1.port: x is not a number`,
+		`orderedmap: backends.1.port: "x" does not match ^\d+$`,
+	}
+	if len(violations) != len(expected) {
+		t.Fatal("Check", violations)
+	}
+	for i, v := range violations {
+		if v.Error() != expected[i] {
+			t.Error("Check", i, v.Error())
+		}
+	}
+	// constraints are not inherited by sub maps
+	pick := o.Pick("name")
+	if violations := pick.Check(); len(violations) != 0 {
+		t.Error("Check on Pick", violations)
+	}
+}
@@ -0,0 +1,109 @@
+package orderedmap
+
+// ToMap returns the map as a plain map[string]interface{}, converting nested
+// maps into plain maps and copying slices that contain them, for use with
+// libraries that do not accept OrderedMap. The key order is lost.
+func (o *OrderedMap) ToMap() map[string]interface{} {
+	if o == nil {
+		return map[string]interface{}{}
+	}
+	m := make(map[string]interface{}, len(o.keys))
+	for _, k := range o.keys {
+		m[k] = toPlain(o.values[k])
+	}
+	return m
+}
+
+func toPlain(v interface{}) interface{} {
+	switch v := v.(type) {
+	case OrderedMap:
+		return v.ToMap()
+	case *OrderedMap:
+		if v == nil {
+			return nil
+		}
+		return v.ToMap()
+	case []OrderedMap:
+		s := make([]interface{}, len(v))
+		for i := range v {
+			s[i] = v[i].ToMap()
+		}
+		return s
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		s := make([]interface{}, len(v))
+		for i, elem := range v {
+			s[i] = toPlain(elem)
+		}
+		return s
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			m[k] = toPlain(elem)
+		}
+		return m
+	}
+	return v
+}
+
+// CloneMapped returns a deep copy of the map in which every value other
+// than a map or slice is replaced by the result of fn, eg to convert
+// json.Numbers into a decimal type while copying. Nested maps and slices
+// are copied, keeping their order and settings. If fn is nil, values are
+// copied as they are.
+func (o *OrderedMap) CloneMapped(fn func(v interface{}) interface{}) OrderedMap {
+	c := o.clone()
+	for k, v := range c.values {
+		c.values[k] = cloneMapped(v, fn)
+	}
+	return *c
+}
+
+func cloneMapped(v interface{}, fn func(interface{}) interface{}) interface{} {
+	switch v := v.(type) {
+	case OrderedMap:
+		return v.CloneMapped(fn)
+	case *OrderedMap:
+		if v == nil {
+			return v
+		}
+		c := v.CloneMapped(fn)
+		return &c
+	case []OrderedMap:
+		if v == nil {
+			return v
+		}
+		s := make([]OrderedMap, len(v))
+		for i := range v {
+			s[i] = v[i].CloneMapped(fn)
+		}
+		return s
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		s := make([]interface{}, len(v))
+		for i, elem := range v {
+			s[i] = cloneMapped(elem, fn)
+		}
+		return s
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			m[k] = cloneMapped(elem, fn)
+		}
+		return m
+	}
+	if fn == nil {
+		return v
+	}
+	return fn(v)
+}
@@ -0,0 +1,68 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToMap(t *testing.T) {
+	o := New()
+	if err := json.Unmarshal([]byte(`{"a":{"b":[{"c":1},2]},"d":"x"}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	p := New()
+	p.Set("y", true)
+	o.Set("p", p)
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": []interface{}{
+				map[string]interface{}{"c": float64(1)},
+				float64(2),
+			},
+		},
+		"d": "x",
+		"p": map[string]interface{}{"y": true},
+	}
+	m := o.ToMap()
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("ToMap %#v", m)
+	}
+	// the result does not share slices with the map
+	m["a"].(map[string]interface{})["b"].([]interface{})[1] = "changed"
+	a, _ := o.GetOrderedMap("a")
+	if b, _ := a.GetSlice("b"); b[1] != float64(2) {
+		t.Error("ToMap result shares a slice with the map")
+	}
+}
+
+func TestCloneMapped(t *testing.T) {
+	o := New()
+	o.SetBigNumbers(true)
+	if err := json.Unmarshal([]byte(`{"b":{"y":1,"x":[2,"s"]},"a":3}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	double := func(v interface{}) interface{} {
+		if f, ok := v.(float64); ok {
+			return f * 2
+		}
+		return v
+	}
+	c := o.CloneMapped(double)
+	b, _ := json.Marshal(c)
+	if string(b) != `{"b":{"y":2,"x":[4,"s"]},"a":6}` {
+		t.Error("CloneMapped", string(b))
+	}
+	// the original is unchanged and shares nothing with the clone
+	c.values["b"].(OrderedMap).values["x"].([]interface{})[1] = "changed"
+	b, _ = json.Marshal(o)
+	if string(b) != `{"b":{"y":1,"x":[2,"s"]},"a":3}` {
+		t.Error("CloneMapped modified the original", string(b))
+	}
+	if !c.bigNumbers {
+		t.Error("CloneMapped did not keep settings")
+	}
+	if plain := o.CloneMapped(nil); !reflect.DeepEqual(plain.ToMap(), o.ToMap()) {
+		t.Error("CloneMapped nil fn")
+	}
+}
@@ -0,0 +1,109 @@
+package orderedmap
+
+import (
+	"fmt"
+	"sync"
+)
+
+// mutationGuard records the keys that are mutated while a MarshalJSON call
+// or an iteration is in progress. It is held by pointer so that every copy
+// of an OrderedMap reports to the same guard.
+type mutationGuard struct {
+	mu      sync.Mutex
+	active  int
+	mutated []mutation
+}
+
+// mutation is a change to key, which was in the map before the change if
+// existed is set.
+type mutation struct {
+	key     string
+	existed bool
+}
+
+// begin marks the start of a marshal or iteration and returns the position
+// in the mutation log to check from when it ends.
+func (g *mutationGuard) begin() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.active++
+	return len(g.mutated)
+}
+
+// end marks the end of a marshal or iteration started at start and returns
+// the mutations made since then.
+func (g *mutationGuard) end(start int) []mutation {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var mutations []mutation
+	if start < len(g.mutated) {
+		mutations = append(mutations, g.mutated[start:]...)
+	}
+	g.active--
+	if g.active == 0 {
+		g.mutated = nil
+	}
+	return mutations
+}
+
+func (g *mutationGuard) record(key string, existed bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.active > 0 {
+		g.mutated = append(g.mutated, mutation{key, existed})
+	}
+}
+
+// mutatedKeys returns the keys of mutations, in order.
+func mutatedKeys(mutations []mutation) []string {
+	keys := make([]string, len(mutations))
+	for i, m := range mutations {
+		keys[i] = m.key
+	}
+	return keys
+}
+
+// endIteration ends an iteration of o by method started at start. It
+// panics if keys were added to or removed from o during the iteration;
+// changing the value of a key is allowed.
+func (g *mutationGuard) endIteration(o *OrderedMap, start int, method string) {
+	if keys := o.addedOrRemoved(g.end(start)); len(keys) > 0 {
+		panic(fmt.Sprintf("orderedmap: keys %q added or removed during %s", keys, method))
+	}
+}
+
+// addedOrRemoved returns the keys of mutations that are no longer in o or
+// are new to it.
+func (o *OrderedMap) addedOrRemoved(mutations []mutation) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, m := range mutations {
+		if seen[m.key] {
+			continue
+		}
+		seen[m.key] = true
+		if _, exists := o.values[m.key]; exists != m.existed {
+			keys = append(keys, m.key)
+		}
+	}
+	return keys
+}
+
+// SetDebug turns on detection of mutations made while the map is being
+// marshalled or iterated, either from another goroutine or from a value's
+// own MarshalJSON or the iteration func. When a mutation is detected
+// MarshalJSON fails with an error naming the offending keys instead of
+// returning inconsistent JSON. ForEach, ForEachPair and AllReverse panic,
+// and Walk returns an error, if keys are added or removed. Detection adds
+// locking to every mutation so it is off by default.
+//
+// This is a debugging aid, not synchronization: a mutation from another
+// goroutine is only detected if it happens while the marshal or iteration
+// is in progress, and is a data race all the same.
+func (o *OrderedMap) SetDebug(on bool) {
+	if !on {
+		o.guard = nil
+	} else if o.guard == nil {
+		o.guard = &mutationGuard{}
+	}
+}
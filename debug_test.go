@@ -0,0 +1,134 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// mutatingValue mutates its parent map while being marshalled.
+type mutatingValue struct {
+	o *OrderedMap
+}
+
+func (m mutatingValue) MarshalJSON() ([]byte, error) {
+	m.o.Set("injected", 1)
+	return []byte(`1`), nil
+}
+
+func TestSetDebug(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", mutatingValue{o})
+	// without debug the mutation goes unnoticed
+	if _, err := json.Marshal(o); err != nil {
+		t.Error("Marshal without debug", err)
+	}
+	o.Delete("injected")
+	o.SetDebug(true)
+	_, err := json.Marshal(o)
+	if err == nil {
+		t.Fatal("Expected error for mutation during marshal")
+	}
+	if !strings.Contains(err.Error(), `"injected"`) {
+		t.Error("Error does not name the mutated key", err)
+	}
+	// mutations outside of marshal are fine
+	o.Set("b", 2)
+	if _, err := json.Marshal(o); err != nil {
+		t.Error("Marshal after mutation", err)
+	}
+	o.SetDebug(false)
+	if o.guard != nil {
+		t.Error("SetDebug(false) did not remove guard")
+	}
+}
+
+// blockingValue hands control to another goroutine while being marshalled
+// and waits for it to finish.
+type blockingValue struct {
+	proceed, done chan struct{}
+}
+
+func (b blockingValue) MarshalJSON() ([]byte, error) {
+	close(b.proceed)
+	<-b.done
+	return []byte(`1`), nil
+}
+
+func TestSetDebugConcurrentWriter(t *testing.T) {
+	o := New()
+	o.SetDebug(true)
+	o.Set("a", 1)
+	v := blockingValue{make(chan struct{}), make(chan struct{})}
+	o.Set("b", v)
+	go func() {
+		<-v.proceed
+		o.Set("a", 2)
+		close(v.done)
+	}()
+	_, err := json.Marshal(o)
+	if err == nil || !strings.Contains(err.Error(), `"a"`) {
+		t.Error("Mutation from another goroutine during marshal", err)
+	}
+}
+
+func TestSetDebugIteration(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":2,"c":3}`)
+	o.SetDebug(true)
+	// changing values while iterating is allowed
+	o.ForEach(func(key string, value interface{}) bool {
+		o.Set(key, 0)
+		return true
+	})
+	o.ForEachPair(func(p Pair) bool {
+		o.Delete("b")
+		o.Set("b", 1)
+		return true
+	})
+
+	expectPanic := func(name string, iterate func()) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			if s, ok := r.(string); !ok || !strings.Contains(s, name) || !strings.Contains(s, `"d"`) {
+				t.Error(name, "did not panic", r)
+			}
+		}()
+		iterate()
+	}
+	expectPanic("ForEach", func() {
+		o.ForEach(func(key string, value interface{}) bool {
+			o.Set("d", 4)
+			return true
+		})
+	})
+	// a writer in another goroutine, run while the iteration is paused
+	expectPanic("ForEachPair", func() {
+		proceed, done := make(chan struct{}), make(chan struct{})
+		go func() {
+			<-proceed
+			o.Delete("d")
+			close(done)
+		}()
+		o.ForEachPair(func(p Pair) bool {
+			if p.Key() == "a" {
+				close(proceed)
+				<-done
+			}
+			return true
+		})
+	})
+	err := o.Walk(func(path []string, value interface{}) error {
+		o.Set("d", 4)
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "Walk") {
+		t.Error("Walk with an added key", err)
+	}
+	o.SetDebug(false)
+	o.ForEach(func(key string, value interface{}) bool {
+		o.Delete("d")
+		return true
+	})
+}
@@ -0,0 +1,80 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeInfo describes anomalies in a JSON document decoded by
+// UnmarshalJSONInfo. Locations are given as JSON Pointers.
+type DecodeInfo struct {
+	// DuplicateKeys holds the location of every repeated occurrence of a
+	// key in an object. Only the last value of a repeated key is kept.
+	DuplicateKeys []string
+	// Depth is the deepest nesting of objects and arrays, counting the
+	// outermost object as 1.
+	Depth int
+	// ImpreciseNumbers holds the location of every number that changes
+	// when decoded as a float64, such as an integer beyond 2^53.
+	// SetBigNumbers decodes such numbers exactly.
+	ImpreciseNumbers []string
+	// InvalidUTF8 holds the location of every key or string containing
+	// invalid UTF-8, which is replaced by U+FFFD when decoding.
+	InvalidUTF8 []string
+}
+
+// UnmarshalJSONInfo decodes b as UnmarshalJSON does and also returns a
+// description of the anomalies found in b, so that risky documents can be
+// flagged without validating them separately.
+func (o *OrderedMap) UnmarshalJSONInfo(b []byte) (DecodeInfo, error) {
+	var info DecodeInfo
+	err := o.unmarshalJSON(b, nil, &info)
+	return info, err
+}
+
+// recordKey records the key being decoded in the last frame of stack,
+// where raw holds its JSON text.
+func (info *DecodeInfo) recordKey(stack []decodeFrame, duplicate bool, raw []byte) {
+	if duplicate {
+		info.DuplicateKeys = append(info.DuplicateKeys, framesPointer(stack))
+	}
+	if !utf8.Valid(raw) {
+		info.InvalidUTF8 = append(info.InvalidUTF8, framesPointer(stack))
+	}
+}
+
+// recordValue records token, the start of the value being decoded in the
+// last frame of stack, where raw holds its JSON text.
+func (info *DecodeInfo) recordValue(stack []decodeFrame, token json.Token, raw []byte) {
+	switch t := token.(type) {
+	case json.Delim:
+		if depth := len(stack) + 1; depth > info.Depth {
+			info.Depth = depth
+		}
+	case json.Number:
+		if _, exact := bigNumber(t).(float64); !exact {
+			info.ImpreciseNumbers = append(info.ImpreciseNumbers, framesPointer(stack))
+		}
+	case string:
+		if strings.ContainsRune(t, utf8.RuneError) && !utf8.Valid(raw) {
+			info.InvalidUTF8 = append(info.InvalidUTF8, framesPointer(stack))
+		}
+	}
+}
+
+// framesPointer returns the JSON Pointer of the value being decoded in the
+// last frame of stack.
+func framesPointer(stack []decodeFrame) string {
+	var b strings.Builder
+	for _, f := range stack {
+		b.WriteByte('/')
+		if f.m != nil {
+			b.WriteString(pointerEscape(f.key))
+		} else {
+			b.WriteString(strconv.Itoa(f.index - 1))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalJSONInfo(t *testing.T) {
+	o := New()
+	input := "{\"a\":1,\"b\":[{\"c\":[9007199254740993]},\"x\xffy\"],\"a\":2,\"k\xfe\":0.1,\"d\":{\"e\":{},\"e\":0.30000000000000000001}}"
+	info, err := o.UnmarshalJSONInfo([]byte(input))
+	if err != nil {
+		t.Fatal("UnmarshalJSONInfo", err)
+	}
+	expected := DecodeInfo{
+		DuplicateKeys:    []string{"/a", "/d/e"},
+		Depth:            4,
+		ImpreciseNumbers: []string{"/b/0/c/0", "/d/e"},
+		InvalidUTF8:      []string{"/b/1", "/k�"},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("UnmarshalJSONInfo %+v", info)
+	}
+	if !reflect.DeepEqual(o.Keys(), []string{"b", "a", "k�", "d"}) {
+		t.Error("UnmarshalJSONInfo keys", o.Keys())
+	}
+	info, err = o.UnmarshalJSONInfo([]byte(`{"a":"�","b":[]}`))
+	if err != nil || !reflect.DeepEqual(info, DecodeInfo{Depth: 2}) {
+		t.Errorf("UnmarshalJSONInfo %+v %v", info, err)
+	}
+}
@@ -0,0 +1,456 @@
+package orderedmap
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	orderedMapType      = reflect.TypeOf(OrderedMap{})
+	orderedMapPtrType   = reflect.TypeOf(&OrderedMap{})
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	errDecodeIntoNonPtr = errors.New("orderedmap: DecodeInto target must be a non-nil pointer")
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	ipType              = reflect.TypeOf(net.IP{})
+)
+
+// DecodeInto stores the entries of the map in the value pointed to by
+// target, following the rules of json.Unmarshal: entries are matched to
+// struct fields by their json tags or names, and nested maps and slices are
+// decoded into nested structs, maps and slices. Values are converted
+// directly, without encoding the map to JSON, except for types implementing
+// json.Unmarshaler, which are given the JSON encoding of their value.
+//
+// Each hook is called in turn with every non-null value before it is
+// converted, and may replace it. A value that is already assignable to its
+// destination after the hooks have run is stored as is.
+func (o *OrderedMap) DecodeInto(target interface{}, hooks ...DecodeHook) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errDecodeIntoNonPtr
+	}
+	d := decodeIntoState{hooks: hooks}
+	return d.decode(nil, *o, rv.Elem())
+}
+
+// DecodeHook converts a value before DecodeInto stores it in a destination
+// of type to. It returns v unchanged if it does not apply.
+type DecodeHook func(to reflect.Type, v interface{}) (interface{}, error)
+
+// StringToTime returns a DecodeHook that parses strings into time.Time
+// using layout.
+func StringToTime(layout string) DecodeHook {
+	return func(to reflect.Type, v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok || to != timeType {
+			return v, nil
+		}
+		return time.Parse(layout, s)
+	}
+}
+
+// StringToDuration is a DecodeHook that parses strings into time.Duration
+// with time.ParseDuration.
+func StringToDuration(to reflect.Type, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || to != durationType {
+		return v, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// StringToIP is a DecodeHook that parses strings into net.IP.
+func StringToIP(to reflect.Type, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || to != ipType {
+		return v, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("orderedmap: invalid IP address %q", s)
+	}
+	return ip, nil
+}
+
+// decodeIntoState holds the options of a DecodeInto call.
+type decodeIntoState struct {
+	hooks []DecodeHook
+}
+
+// decodeError describes a value that cannot be stored in dst.
+func decodeError(path []string, v interface{}, dst reflect.Value) error {
+	return fmt.Errorf("orderedmap: cannot decode %T into %s at %q", v, dst.Type(), strings.Join(path, "."))
+}
+
+func (d *decodeIntoState) decode(path []string, v interface{}, dst reflect.Value) error {
+	v = derefMap(v)
+	if v == nil {
+		switch dst.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		return nil
+	}
+	for _, hook := range d.hooks {
+		var err error
+		if v, err = hook(dst.Type(), v); err != nil {
+			return fmt.Errorf("orderedmap: decoding %q: %w", strings.Join(path, "."), err)
+		}
+	}
+	if v == nil {
+		return d.decode(path, nil, dst)
+	}
+	if reflect.TypeOf(v).AssignableTo(dst.Type()) {
+		dst.Set(reflect.ValueOf(v))
+		return nil
+	}
+	switch dst.Type() {
+	case orderedMapType:
+		if m, ok := v.(OrderedMap); ok {
+			dst.Set(reflect.ValueOf(m))
+			return nil
+		}
+		return decodeError(path, v, dst)
+	case orderedMapPtrType:
+		if m, ok := v.(OrderedMap); ok {
+			dst.Set(reflect.ValueOf(&m))
+			return nil
+		}
+		return decodeError(path, v, dst)
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return d.decode(path, v, dst.Elem())
+	}
+	if dst.CanAddr() {
+		if dst.Addr().Type().Implements(jsonUnmarshalerType) {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			return dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(b)
+		}
+		if s, ok := v.(string); ok && dst.Addr().Type().Implements(textUnmarshalerType) {
+			return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+	}
+	switch dst.Kind() {
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return decodeError(path, v, dst)
+		}
+		dst.Set(reflect.ValueOf(v))
+	case reflect.Struct:
+		return d.decodeStruct(path, v, dst)
+	case reflect.Map:
+		return d.decodeMap(path, v, dst)
+	case reflect.Slice, reflect.Array:
+		return d.decodeSlice(path, v, dst)
+	case reflect.String:
+		switch s := v.(type) {
+		case string:
+			dst.SetString(s)
+		case json.Number:
+			dst.SetString(s.String())
+		default:
+			return decodeError(path, v, dst)
+		}
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return decodeError(path, v, dst)
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(v)
+		if !ok || dst.OverflowInt(i) {
+			return decodeError(path, v, dst)
+		}
+		dst.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, ok := toInt64(v)
+		if !ok || i < 0 || dst.OverflowUint(uint64(i)) {
+			return decodeError(path, v, dst)
+		}
+		dst.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(v)
+		if !ok || dst.OverflowFloat(f) {
+			return decodeError(path, v, dst)
+		}
+		dst.SetFloat(f)
+	default:
+		return decodeError(path, v, dst)
+	}
+	return nil
+}
+
+// entries returns the keys and values of a map value in order.
+func entries(v interface{}) ([]string, func(key string) interface{}, bool) {
+	switch m := v.(type) {
+	case OrderedMap:
+		return m.keys, func(key string) interface{} { return m.values[key] }, true
+	case map[string]interface{}:
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		return keys, func(key string) interface{} { return m[key] }, true
+	}
+	return nil, nil, false
+}
+
+func (d *decodeIntoState) decodeStruct(path []string, v interface{}, dst reflect.Value) error {
+	keys, get, ok := entries(v)
+	if !ok {
+		return decodeError(path, v, dst)
+	}
+	fields := structFields(dst.Type())
+	for _, k := range keys {
+		f, ok := fields.lookup(k)
+		if !ok {
+			continue
+		}
+		fv, err := fieldByIndex(dst, f.index)
+		if err != nil {
+			return err
+		}
+		if err := d.decode(append(path[:len(path):len(path)], k), get(k), fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *decodeIntoState) decodeMap(path []string, v interface{}, dst reflect.Value) error {
+	keys, get, ok := entries(v)
+	if !ok {
+		return decodeError(path, v, dst)
+	}
+	t := dst.Type()
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(t, len(keys)))
+	}
+	for _, k := range keys {
+		kv := reflect.New(t.Key()).Elem()
+		switch {
+		case reflect.PtrTo(t.Key()).Implements(textUnmarshalerType):
+			if err := kv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+				return err
+			}
+		case t.Key().Kind() == reflect.String:
+			kv.SetString(k)
+		case t.Key().Kind() >= reflect.Int && t.Key().Kind() <= reflect.Int64:
+			i, err := strconv.ParseInt(k, 10, 64)
+			if err != nil || kv.OverflowInt(i) {
+				return decodeError(path, k, kv)
+			}
+			kv.SetInt(i)
+		case t.Key().Kind() >= reflect.Uint && t.Key().Kind() <= reflect.Uintptr:
+			u, err := strconv.ParseUint(k, 10, 64)
+			if err != nil || kv.OverflowUint(u) {
+				return decodeError(path, k, kv)
+			}
+			kv.SetUint(u)
+		default:
+			return decodeError(path, v, dst)
+		}
+		ev := reflect.New(t.Elem()).Elem()
+		if err := d.decode(append(path[:len(path):len(path)], k), get(k), ev); err != nil {
+			return err
+		}
+		dst.SetMapIndex(kv, ev)
+	}
+	return nil
+}
+
+func (d *decodeIntoState) decodeSlice(path []string, v interface{}, dst reflect.Value) error {
+	if s, ok := v.(string); ok && dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Uint8 {
+		// []byte is encoded as base64, as in encoding/json
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		dst.SetBytes(b)
+		return nil
+	}
+	src := reflect.ValueOf(v)
+	if src.Kind() != reflect.Slice {
+		return decodeError(path, v, dst)
+	}
+	n := src.Len()
+	if dst.Kind() == reflect.Slice {
+		dst.Set(reflect.MakeSlice(dst.Type(), n, n))
+	}
+	for i := 0; i < n && i < dst.Len(); i++ {
+		if err := d.decode(append(path[:len(path):len(path)], strconv.Itoa(i)), src.Index(i).Interface(), dst.Index(i)); err != nil {
+			return err
+		}
+	}
+	// zero any remaining array elements, as encoding/json does
+	for i := n; i < dst.Len(); i++ {
+		dst.Index(i).Set(reflect.Zero(dst.Type().Elem()))
+	}
+	return nil
+}
+
+// field is an exported struct field as named by encoding/json.
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	tagged    bool
+	tag       reflect.StructTag
+}
+
+type fieldList []field
+
+// lookup finds the field for key, preferring an exact match of the name to
+// a case-insensitive one as encoding/json does.
+func (fields fieldList) lookup(key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// structFields returns the fields of t named as encoding/json names them,
+// including the fields of embedded structs. Where several fields share a
+// name, the shallowest wins, then the only tagged one among the
+// shallowest; if that still leaves more than one, all of them are dropped.
+func structFields(t reflect.Type) fieldList {
+	all := embeddedFields(t)
+	byName := map[string][]field{}
+	for _, f := range all {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	var fields fieldList
+	for _, f := range all {
+		if d, ok := dominantField(byName[f.name]); ok && sameIndex(d.index, f.index) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// embeddedFields returns every named field of t, including those promoted
+// from embedded structs, in index order.
+func embeddedFields(t reflect.Type) fieldList {
+	var fields fieldList
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for _, f := range embeddedFields(ft) {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = sf.Name
+		}
+		fields = append(fields, field{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(tag, ",omitempty"),
+			tagged:    tagged,
+			tag:       sf.Tag,
+		})
+	}
+	return fields
+}
+
+// dominantField picks the field that encoding/json uses among fields with
+// the same name, reporting false if none dominates.
+func dominantField(fields []field) (field, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields[1:] {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+	var dominant field
+	found, tagged := 0, 0
+	for _, f := range fields {
+		if len(f.index) != depth {
+			continue
+		}
+		if f.tagged {
+			if tagged == 0 || !dominant.tagged {
+				dominant = f
+			}
+			tagged++
+		} else if found == 0 {
+			dominant = f
+		}
+		found++
+	}
+	if found == 1 || tagged == 1 {
+		return dominant, true
+	}
+	return field{}, false
+}
+
+// sameIndex reports whether a and b are the same field index.
+func sameIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldByIndex returns the field of v at index, allocating any nil embedded
+// struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("orderedmap: cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
@@ -0,0 +1,201 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type decodeIntoInner struct {
+	X int `json:"x"`
+}
+
+type decodeIntoEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type decodeIntoTarget struct {
+	decodeIntoEmbedded
+	Name    string           `json:"name"`
+	Count   uint8            `json:"count"`
+	Ratio   float32          `json:"ratio"`
+	Ok      bool             `json:"ok"`
+	Tags    []string         `json:"tags"`
+	Pair    [2]int           `json:"pair"`
+	Inner   decodeIntoInner  `json:"inner"`
+	Ptr     *decodeIntoInner `json:"ptr"`
+	Map     map[string]int   `json:"map"`
+	IntKeys map[int]string   `json:"int_keys"`
+	Any     interface{}      `json:"any"`
+	Nested  OrderedMap       `json:"nested"`
+	Raw     []byte           `json:"raw"`
+	When    time.Time        `json:"when"`
+	Skipped string           `json:"-"`
+	Plain   string
+	Items   []decodeIntoInner `json:"items"`
+}
+
+func TestDecodeInto(t *testing.T) {
+	o := mustUnmarshal(t, `{
+		"embedded": "e",
+		"name": "n",
+		"count": 3,
+		"ratio": 0.5,
+		"ok": true,
+		"tags": ["a", "b"],
+		"pair": [1, 2],
+		"inner": {"x": 1},
+		"ptr": {"x": 2},
+		"map": {"a": 1},
+		"int_keys": {"7": "seven"},
+		"any": {"z": 1, "a": 2},
+		"nested": {"z": 1, "a": 2},
+		"raw": "aGk=",
+		"when": "2020-01-02T03:04:05Z",
+		"-": "ignored",
+		"PLAIN": "p",
+		"items": [{"x": 3}, {"x": 4}],
+		"unknown": 1
+	}`)
+	var target decodeIntoTarget
+	if err := o.DecodeInto(&target); err != nil {
+		t.Fatal("DecodeInto", err)
+	}
+	expected := decodeIntoTarget{
+		decodeIntoEmbedded: decodeIntoEmbedded{"e"},
+		Name:               "n",
+		Count:              3,
+		Ratio:              0.5,
+		Ok:                 true,
+		Tags:               []string{"a", "b"},
+		Pair:               [2]int{1, 2},
+		Inner:              decodeIntoInner{1},
+		Ptr:                &decodeIntoInner{2},
+		Map:                map[string]int{"a": 1},
+		IntKeys:            map[int]string{7: "seven"},
+		Raw:                []byte("hi"),
+		When:               time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Plain:              "p",
+		Items:              []decodeIntoInner{{3}, {4}},
+	}
+	// maps keep their order
+	if m, ok := target.Any.(OrderedMap); !ok || !reflect.DeepEqual(m.Keys(), []string{"z", "a"}) {
+		t.Error("DecodeInto interface", target.Any)
+	}
+	if !reflect.DeepEqual(target.Nested.Keys(), []string{"z", "a"}) {
+		t.Error("DecodeInto OrderedMap", target.Nested.Keys())
+	}
+	target.Any = nil
+	target.Nested = OrderedMap{}
+	if !reflect.DeepEqual(target, expected) {
+		t.Errorf("DecodeInto\n%+v\n!=\n%+v", target, expected)
+	}
+}
+
+type shadowBase struct {
+	Name  string
+	Both  string
+	Tied  string
+	Outer string `json:"outer"`
+}
+
+type shadowOther struct {
+	Both string `json:"Both"`
+	Tied string
+}
+
+type shadowTarget struct {
+	shadowBase
+	shadowOther
+	Name string
+}
+
+func TestDecodeIntoShadowedFields(t *testing.T) {
+	o := mustUnmarshal(t, `{"Name":"x","Both":"b","Tied":"t","outer":"o"}`)
+	var target shadowTarget
+	if err := o.DecodeInto(&target); err != nil {
+		t.Fatal("DecodeInto", err)
+	}
+	var expected shadowTarget
+	if err := json.Unmarshal([]byte(`{"Name":"x","Both":"b","Tied":"t","outer":"o"}`), &expected); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	// the outer Name wins, the tagged Both wins, and the tied Tied is dropped
+	if target.Name != "x" || target.shadowBase.Name != "" || target.Outer != "o" {
+		t.Error("DecodeInto shadowed", target)
+	}
+	if target.shadowOther.Both != "b" || target.shadowBase.Both != "" || target.shadowBase.Tied != "" || target.shadowOther.Tied != "" {
+		t.Error("DecodeInto tagged or tied", target)
+	}
+	if target != expected {
+		t.Error("DecodeInto differs from encoding/json", target, expected)
+	}
+
+	v := shadowTarget{shadowBase{"inner", "base", "tied", "o"}, shadowOther{"other", "tied"}, "outer"}
+	filled := New()
+	if err := filled.FillFromStruct(v, false); err != nil {
+		t.Fatal("FillFromStruct", err)
+	}
+	b, _ := json.Marshal(filled)
+	want, _ := json.Marshal(v)
+	if string(b) != string(want) {
+		t.Error("FillFromStruct shadowed", string(b), string(want))
+	}
+}
+
+func TestDecodeIntoErrors(t *testing.T) {
+	o := mustUnmarshal(t, `{"count": 300}`)
+	var target decodeIntoTarget
+	if err := o.DecodeInto(&target); err == nil {
+		t.Error("DecodeInto overflow did not fail")
+	}
+	o = mustUnmarshal(t, `{"inner": {"x": "one"}}`)
+	err := o.DecodeInto(&target)
+	if err == nil || err.Error() != `orderedmap: cannot decode string into int at "inner.x"` {
+		t.Error("DecodeInto type mismatch", err)
+	}
+	if err := o.DecodeInto(target); err == nil {
+		t.Error("DecodeInto non-pointer did not fail")
+	}
+	// null leaves values as they are, and clears pointers
+	target = decodeIntoTarget{Name: "kept", Ptr: &decodeIntoInner{}}
+	o = mustUnmarshal(t, `{"name": null, "ptr": null}`)
+	if err := o.DecodeInto(&target); err != nil || target.Name != "kept" || target.Ptr != nil {
+		t.Error("DecodeInto null", err, target)
+	}
+}
+
+func TestDecodeIntoHooks(t *testing.T) {
+	type config struct {
+		Start   time.Time     `json:"start"`
+		Timeout time.Duration `json:"timeout"`
+		Addr    net.IP        `json:"addr"`
+		Port    *int          `json:"port"`
+	}
+	o := mustUnmarshal(t, `{"start":"2021-03-04","timeout":"1m30s","addr":"10.0.0.1","port":"8080"}`)
+	portHook := func(to reflect.Type, v interface{}) (interface{}, error) {
+		if s, ok := v.(string); ok && to.Kind() == reflect.Int {
+			return strconv.Atoi(s)
+		}
+		return v, nil
+	}
+	var c config
+	err := o.DecodeInto(&c, StringToTime("2006-01-02"), StringToDuration, StringToIP, portHook)
+	if err != nil {
+		t.Fatal("DecodeInto", err)
+	}
+	if !c.Start.Equal(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)) ||
+		c.Timeout != 90*time.Second ||
+		!c.Addr.Equal(net.IPv4(10, 0, 0, 1)) ||
+		c.Port == nil || *c.Port != 8080 {
+		t.Error("DecodeInto hooks", c)
+	}
+	o = mustUnmarshal(t, `{"addr":"nope"}`)
+	err = o.DecodeInto(&c, StringToIP)
+	if err == nil || err.Error() != `orderedmap: decoding "addr": orderedmap: invalid IP address "nope"` {
+		t.Error("DecodeInto hook error", err)
+	}
+}
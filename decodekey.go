@@ -0,0 +1,121 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetRetainRaw sets whether UnmarshalJSON keeps the raw JSON of each value
+// in the map, so that DecodeKey and DecodePath can decode straight from the
+// original bytes. The raw JSON of a key is discarded when the key is set or
+// deleted, but changes made inside nested maps are not tracked, so DecodeKey
+// and DecodePath return the value as it was decoded.
+func (o *OrderedMap) SetRetainRaw(on bool) {
+	o.retainRaw = on
+	if !on {
+		o.raw = nil
+	}
+}
+
+// SetRaw sets the value of key to an already encoded JSON value, which is
+// written in place of the value when the map is marshalled, compacted and
+// with HTML characters escaped as for other values. raw must be valid JSON
+// or marshalling fails.
+func (o *OrderedMap) SetRaw(key string, raw json.RawMessage) {
+	o.Set(key, raw)
+}
+
+// GetRaw returns the encoded JSON of the value of key if it was set with
+// SetRaw or retained by SetRetainRaw, without encoding it again. It returns
+// false for other values and missing keys.
+func (o *OrderedMap) GetRaw(key string) (json.RawMessage, bool) {
+	if o == nil {
+		return nil, false
+	}
+	key = o.canonicalKey(key)
+	if raw, ok := o.values[key].(json.RawMessage); ok {
+		return raw, true
+	}
+	raw, ok := o.raw[key]
+	return raw, ok
+}
+
+// DecodeKey unmarshals the value of key into target, which must be a
+// pointer, eg to a struct.
+func (o *OrderedMap) DecodeKey(key string, target interface{}) error {
+	return o.DecodePath([]string{key}, target)
+}
+
+// DecodePath unmarshals the value at path into target, which must be a
+// pointer. Each element of path is a key in a nested map or an index in a
+// nested array. The raw JSON retained by SetRetainRaw is used when it is
+// available, otherwise the value is encoded to JSON and decoded into target.
+func (o *OrderedMap) DecodePath(path []string, target interface{}) error {
+	if len(path) == 0 {
+		return fmt.Errorf("orderedmap: empty path")
+	}
+	if o == nil {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, path[0])
+	}
+	if raw, ok := o.raw[path[0]]; ok {
+		for i, segment := range path[1:] {
+			next, err := rawChild(raw, segment)
+			if err != nil {
+				return fmt.Errorf("orderedmap: %s: %w", strings.Join(path[:i+2], "/"), err)
+			}
+			raw = next
+		}
+		return json.Unmarshal(raw, target)
+	}
+	var v interface{} = o
+	for i, segment := range path {
+		next, ok := childValue(v, segment)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrKeyNotFound, strings.Join(path[:i+1], "/"))
+		}
+		v = next
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, target)
+}
+
+// childValue returns the value for segment in the map or slice v.
+func childValue(v interface{}, segment string) (interface{}, bool) {
+	switch v := v.(type) {
+	case *OrderedMap:
+		return v.Get(segment)
+	case OrderedMap:
+		return v.Get(segment)
+	case map[string]interface{}:
+		c, ok := v[segment]
+		return c, ok
+	case []interface{}:
+		if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(v) {
+			return v[i], true
+		}
+	}
+	return nil, false
+}
+
+// rawChild returns the raw JSON for segment in the raw object or array.
+func rawChild(raw json.RawMessage, segment string) (json.RawMessage, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err == nil {
+		if c, ok := object[segment]; ok {
+			return c, nil
+		}
+		return nil, ErrKeyNotFound
+	}
+	var array []json.RawMessage
+	if err := json.Unmarshal(raw, &array); err == nil {
+		if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(array) {
+			return array[i], nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
@@ -0,0 +1,105 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type decodeKeyServer struct {
+	Host  string `json:"host"`
+	Port  int    `json:"port"`
+	Large int64  `json:"large"`
+}
+
+const decodeKeyInput = `{
+  "name": "test",
+  "servers": [
+    {"host": "a", "port": 80, "large": 9007199254740993},
+    {"host": "b", "port": 81, "large": 1}
+  ],
+  "primary": {"host": "c", "port": 82, "large": 9007199254740993}
+}`
+
+func TestDecodeKey(t *testing.T) {
+	for _, retainRaw := range []bool{false, true} {
+		o := New()
+		o.SetRetainRaw(retainRaw)
+		if err := json.Unmarshal([]byte(decodeKeyInput), o); err != nil {
+			t.Fatal("Unmarshal", err)
+		}
+		var primary decodeKeyServer
+		if err := o.DecodeKey("primary", &primary); err != nil {
+			t.Fatal("DecodeKey", err)
+		}
+		if primary.Host != "c" || primary.Port != 82 {
+			t.Errorf("DecodeKey retainRaw=%v: %+v", retainRaw, primary)
+		}
+		// only the raw bytes keep the precision of large numbers
+		if retainRaw && primary.Large != 9007199254740993 {
+			t.Error("DecodeKey from raw lost precision", primary.Large)
+		}
+		var second decodeKeyServer
+		if err := o.DecodePath([]string{"servers", "1"}, &second); err != nil {
+			t.Fatal("DecodePath", err)
+		}
+		if second.Host != "b" || second.Port != 81 {
+			t.Errorf("DecodePath retainRaw=%v: %+v", retainRaw, second)
+		}
+		var host string
+		if err := o.DecodePath([]string{"servers", "0", "host"}, &host); err != nil || host != "a" {
+			t.Error("DecodePath to string", host, err)
+		}
+		if err := o.DecodePath([]string{"servers", "2"}, &second); !errors.Is(err, ErrKeyNotFound) {
+			t.Error("DecodePath out of range", err)
+		}
+		if err := o.DecodeKey("missing", &second); !errors.Is(err, ErrKeyNotFound) {
+			t.Error("DecodeKey missing", err)
+		}
+		// setting a key discards its raw json
+		replacement := New()
+		replacement.Set("host", "d")
+		o.Set("primary", replacement)
+		if err := o.DecodeKey("primary", &primary); err != nil || primary.Host != "d" {
+			t.Error("DecodeKey after Set", primary, err)
+		}
+	}
+}
+
+func TestSetRaw(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.SetRaw("b", json.RawMessage(`{"z": 1, "y": [true]}`))
+	o.Set("c", 2)
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"a":1,"b":{"z":1,"y":[true]},"c":2}` {
+		t.Error("SetRaw", string(b))
+	}
+	if raw, ok := o.GetRaw("b"); !ok || string(raw) != `{"z": 1, "y": [true]}` {
+		t.Error("GetRaw", string(raw), ok)
+	}
+	if _, ok := o.GetRaw("a"); ok {
+		t.Error("GetRaw of a decoded value")
+	}
+	var target struct{ Y []bool }
+	if err := o.DecodeKey("b", &target); err != nil || len(target.Y) != 1 {
+		t.Error("DecodeKey of raw value", err, target)
+	}
+
+	r := New()
+	r.SetRetainRaw(true)
+	if err := json.Unmarshal([]byte(`{"x": [1, 2]}`), r); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if raw, ok := r.GetRaw("x"); !ok || string(raw) != `[1, 2]` {
+		t.Error("GetRaw of retained value", string(raw), ok)
+	}
+
+	o.SetRaw("d", json.RawMessage(`{`))
+	if _, err := json.Marshal(o); err == nil {
+		t.Error("Marshal of invalid raw value did not fail")
+	}
+}
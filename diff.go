@@ -0,0 +1,135 @@
+package orderedmap
+
+import (
+	"strconv"
+)
+
+// Operation is an operation of a JSON Patch document (RFC 6902), as
+// returned by Diff.
+type Operation struct {
+	// Op is "add", "remove", "replace" or "move".
+	Op string
+	// Path is the JSON Pointer of the value the operation changes.
+	Path string
+	// From is the JSON Pointer of the value moved by a move operation.
+	From string
+	// Value is the value of an add or replace operation.
+	Value interface{}
+}
+
+// MarshalJSON writes the operation as a JSON Patch operation, with only
+// the members used by its kind.
+func (op Operation) MarshalJSON() ([]byte, error) {
+	o := New()
+	o.Set("op", op.Op)
+	if op.Op == "move" || op.Op == "copy" {
+		o.Set("from", op.From)
+	}
+	o.Set("path", op.Path)
+	if op.Op == "add" || op.Op == "replace" || op.Op == "test" {
+		o.Set("value", op.Value)
+	}
+	return o.MarshalJSON()
+}
+
+// Diff returns a JSON Patch that turns a into b when applied with
+// ApplyPatch, ignoring the order of keys. Changed keys are replaced or
+// diffed recursively if both values are maps or arrays, removed keys are
+// removed and new keys are added in the order of b. Arrays are diffed
+// element by element after skipping their common beginning and end.
+func Diff(a, b OrderedMap) ([]Operation, error) {
+	d := differ{}
+	d.diffMaps("", &a, &b)
+	return d.ops, nil
+}
+
+// DiffOrdered returns a JSON Patch that turns a into b as Diff does, and
+// also reorders the keys of every map to match b, by moving keys that are
+// out of order to the end of their map with move operations from a key to
+// itself.
+func DiffOrdered(a, b OrderedMap) ([]Operation, error) {
+	d := differ{ordered: true}
+	d.diffMaps("", &a, &b)
+	return d.ops, nil
+}
+
+type differ struct {
+	ordered bool
+	ops     []Operation
+}
+
+func (d *differ) diffMaps(path string, a, b *OrderedMap) {
+	for _, k := range a.keys {
+		if _, ok := b.values[k]; !ok {
+			d.ops = append(d.ops, Operation{Op: "remove", Path: path + "/" + pointerEscape(k)})
+		}
+	}
+	for _, k := range a.keys {
+		if bv, ok := b.values[k]; ok {
+			d.diffValues(path+"/"+pointerEscape(k), a.values[k], bv)
+		}
+	}
+	// the keys of b from the first that is out of order are added or moved
+	// to the end
+	start := len(b.keys)
+	if d.ordered {
+		start = 0
+		for _, k := range a.keys {
+			if start < len(b.keys) && b.keys[start] == k {
+				start++
+			}
+		}
+	}
+	for i, k := range b.keys {
+		_, inA := a.values[k]
+		keyPath := path + "/" + pointerEscape(k)
+		switch {
+		case !inA:
+			d.ops = append(d.ops, Operation{Op: "add", Path: keyPath, Value: b.values[k]})
+		case i >= start:
+			d.ops = append(d.ops, Operation{Op: "move", From: keyPath, Path: keyPath})
+		}
+	}
+}
+
+func (d *differ) diffArrays(path string, a, b []interface{}) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && valuesEqual(a[prefix], b[prefix], d.ordered) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		valuesEqual(a[len(a)-1-suffix], b[len(b)-1-suffix], d.ordered) {
+		suffix++
+	}
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	i := 0
+	for ; i < len(a) && i < len(b); i++ {
+		d.diffValues(path+"/"+strconv.Itoa(prefix+i), a[i], b[i])
+	}
+	for j := i; j < len(a); j++ {
+		// each removal shifts the remaining elements down
+		d.ops = append(d.ops, Operation{Op: "remove", Path: path + "/" + strconv.Itoa(prefix+i)})
+	}
+	for ; i < len(b); i++ {
+		d.ops = append(d.ops, Operation{Op: "add", Path: path + "/" + strconv.Itoa(prefix+i), Value: b[i]})
+	}
+}
+
+func (d *differ) diffValues(path string, a, b interface{}) {
+	if am, ok := asMap(a); ok {
+		if bm, ok := asMap(b); ok {
+			d.diffMaps(path, am, bm)
+			return
+		}
+	}
+	if as, ok := a.([]interface{}); ok {
+		if bs, ok := b.([]interface{}); ok {
+			d.diffArrays(path, as, bs)
+			return
+		}
+	}
+	if !valuesEqual(a, b, d.ordered) {
+		d.ops = append(d.ops, Operation{Op: "replace", Path: path, Value: b})
+	}
+}
@@ -0,0 +1,84 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := mustUnmarshal(t, `{"name":"x","port":80,"tags":["a","b","c","d"],"tls":{"on":false,"cert":"c"},"old":1,"list":[1,2]}`)
+	b := mustUnmarshal(t, `{"tls":{"cert":"c","on":true},"port":443,"name":"x","tags":["a","x","d","e"],"list":{"v":1},"new":[1]}`)
+	ops, err := Diff(a, b)
+	if err != nil {
+		t.Fatal("Diff", err)
+	}
+	patch, _ := json.Marshal(ops)
+	expected := `[{"op":"remove","path":"/old"},` +
+		`{"op":"replace","path":"/port","value":443},` +
+		`{"op":"replace","path":"/tags/1","value":"x"},` +
+		`{"op":"replace","path":"/tags/2","value":"d"},` +
+		`{"op":"replace","path":"/tags/3","value":"e"},` +
+		`{"op":"replace","path":"/tls/on","value":true},` +
+		`{"op":"replace","path":"/list","value":{"v":1}},` +
+		`{"op":"add","path":"/new","value":[1]}]`
+	if string(patch) != expected {
+		t.Error("Diff", string(patch))
+	}
+	c := a.CloneMapped(nil)
+	if err := c.ApplyPatch(patch); err != nil {
+		t.Fatal("ApplyPatch", err)
+	}
+	if !valuesEqual(c, b, false) {
+		t.Error("Diff result does not produce b")
+	}
+
+	ops, _ = DiffOrdered(a, b)
+	patch, _ = json.Marshal(ops)
+	c = a.CloneMapped(nil)
+	if err := c.ApplyPatch(patch); err != nil {
+		t.Fatal("ApplyPatch", err)
+	}
+	if !valuesEqual(c, b, true) {
+		got, _ := json.Marshal(c)
+		t.Error("DiffOrdered result does not produce b", string(got), string(patch))
+	}
+	moves := 0
+	for _, op := range ops {
+		if op.Op == "move" {
+			moves++
+		}
+	}
+	// tls stays first and the other keys are moved after it in order, and
+	// within tls on is moved after cert
+	if moves != 5 {
+		t.Error("DiffOrdered moves", string(patch))
+	}
+
+	if ops, _ := Diff(a, a); len(ops) != 0 {
+		t.Error("Diff of equal maps", ops)
+	}
+}
+
+func TestDiffArrays(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{`[1,2,3]`, `[1,3]`},
+		{`[1,3]`, `[1,2,3]`},
+		{`[1,2,3,4,5]`, `[1,5]`},
+		{`[]`, `[1,2]`},
+		{`[1,2]`, `[]`},
+		{`[[1,{"a":1}]]`, `[[1,{"a":2},3]]`},
+	}
+	for _, test := range tests {
+		a := mustUnmarshal(t, `{"v":`+test.a+`}`)
+		b := mustUnmarshal(t, `{"v":`+test.b+`}`)
+		ops, _ := Diff(a, b)
+		patch, _ := json.Marshal(ops)
+		if err := a.ApplyPatch(patch); err != nil {
+			t.Error("ApplyPatch", test.a, test.b, err)
+			continue
+		}
+		if !valuesEqual(a, b, false) {
+			t.Error("Diff", test.a, test.b, string(patch))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package orderedmap
+
+// Change is the kind of change made to a key, as reported by DirtyKeys.
+type Change int
+
+const (
+	// ChangeAdded is a key that was not in the map.
+	ChangeAdded Change = iota + 1
+	// ChangeModified is a key whose value was set.
+	ChangeModified
+	// ChangeDeleted is a key that was removed from the map.
+	ChangeDeleted
+)
+
+// KeyChange is a key changed since change tracking started.
+type KeyChange struct {
+	Key    string
+	Change Change
+}
+
+// dirtyTracker records whether each key changed since tracking started was
+// in the map before its first change.
+type dirtyTracker struct {
+	keys    []string
+	existed map[string]bool
+}
+
+func newDirtyTracker() *dirtyTracker {
+	return &dirtyTracker{existed: map[string]bool{}}
+}
+
+func (d *dirtyTracker) record(key string, values map[string]interface{}) {
+	if _, seen := d.existed[key]; seen {
+		return
+	}
+	_, exists := values[key]
+	d.existed[key] = exists
+	d.keys = append(d.keys, key)
+}
+
+func (d *dirtyTracker) clone() *dirtyTracker {
+	c := newDirtyTracker()
+	c.keys = append(c.keys, d.keys...)
+	for k, v := range d.existed {
+		c.existed[k] = v
+	}
+	return c
+}
+
+// SetTrackChanges sets whether the map records the keys that are added,
+// set or deleted, for DirtyKeys. Turning tracking on starts from a clean
+// state, and turning it off discards what was recorded. UnmarshalJSON
+// replaces the whole map and resets tracking, as ResetDirty does, so the
+// decoded document is the state later changes are relative to. Changes inside
+// nested maps are only recorded if they are stored again in the map, as
+// SetPath does.
+func (o *OrderedMap) SetTrackChanges(on bool) {
+	o.dirty = nil
+	if on {
+		o.dirty = newDirtyTracker()
+	}
+}
+
+// ResetDirty forgets the changes recorded so far, eg after the map has been
+// persisted.
+func (o *OrderedMap) ResetDirty() {
+	if o.dirty != nil {
+		o.dirty = newDirtyTracker()
+	}
+}
+
+// DirtyKeys returns the keys changed since SetTrackChanges or ResetDirty
+// was called, in the order they were first changed, with their net change.
+// A key that was set again is modified even if its value is the same, and
+// a key that was added and then deleted is not reported.
+func (o *OrderedMap) DirtyKeys() []KeyChange {
+	if o == nil || o.dirty == nil {
+		return nil
+	}
+	var changes []KeyChange
+	for _, k := range o.dirty.keys {
+		_, exists := o.values[k]
+		switch existed := o.dirty.existed[k]; {
+		case existed && exists:
+			changes = append(changes, KeyChange{k, ChangeModified})
+		case existed:
+			changes = append(changes, KeyChange{k, ChangeDeleted})
+		case exists:
+			changes = append(changes, KeyChange{k, ChangeAdded})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,66 @@
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDirtyKeys(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":2,"c":3,"d":{"x":1}}`)
+	o.Set("a", 10)
+	if o.DirtyKeys() != nil {
+		t.Error("DirtyKeys without tracking", o.DirtyKeys())
+	}
+	o.SetTrackChanges(true)
+	o.Set("b", 20)
+	o.Set("n", 1)
+	o.Delete("c")
+	o.Set("tmp", 1)
+	o.Delete("tmp")
+	o.Set("b", 21)
+	if err := o.SetPath([]string{"d", "x"}, 2); err != nil {
+		t.Fatal("SetPath", err)
+	}
+	expected := []KeyChange{
+		{"b", ChangeModified},
+		{"n", ChangeAdded},
+		{"c", ChangeDeleted},
+		{"d", ChangeModified},
+	}
+	if changes := o.DirtyKeys(); !reflect.DeepEqual(changes, expected) {
+		t.Error("DirtyKeys", changes)
+	}
+	// a deleted key that is set again is modified
+	o.Set("c", 3)
+	if changes := o.DirtyKeys(); changes[2] != (KeyChange{"c", ChangeModified}) {
+		t.Error("DirtyKeys after re-adding", changes)
+	}
+	o.ResetDirty()
+	if changes := o.DirtyKeys(); len(changes) != 0 {
+		t.Error("DirtyKeys after ResetDirty", changes)
+	}
+	if err := o.ApplyPatch([]byte(`[{"op":"remove","path":"/a"},{"op":"add","path":"/z","value":1}]`)); err != nil {
+		t.Fatal("ApplyPatch", err)
+	}
+	expected = []KeyChange{{"a", ChangeDeleted}, {"z", ChangeAdded}}
+	if changes := o.DirtyKeys(); !reflect.DeepEqual(changes, expected) {
+		t.Error("DirtyKeys after ApplyPatch", changes)
+	}
+	// decoding starts tracking afresh
+	o.Set("a", 1)
+	if err := o.UnmarshalJSON([]byte(`{"x":1,"y":2}`)); err != nil {
+		t.Fatal("UnmarshalJSON", err)
+	}
+	if changes := o.DirtyKeys(); len(changes) != 0 {
+		t.Error("DirtyKeys after UnmarshalJSON", changes)
+	}
+	o.Set("x", 2)
+	if changes := o.DirtyKeys(); !reflect.DeepEqual(changes, []KeyChange{{"x", ChangeModified}}) {
+		t.Error("DirtyKeys after decoding and Set", changes)
+	}
+	o.SetTrackChanges(false)
+	o.Set("y", 1)
+	if o.DirtyKeys() != nil {
+		t.Error("DirtyKeys after tracking stopped", o.DirtyKeys())
+	}
+}
@@ -0,0 +1,106 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// EscapeProfile selects which characters are escaped in the keys and string
+// values written by MarshalJSON.
+type EscapeProfile int
+
+const (
+	// EscapeDefault escapes as encoding/json does, with HTML characters
+	// controlled by SetEscapeHTML.
+	EscapeDefault EscapeProfile = iota
+	// EscapeMinimal escapes only what RFC 8259 requires: quotation mark,
+	// reverse solidus and control characters.
+	EscapeMinimal
+	// EscapeHTMLSafe additionally escapes <, > and & so the output can be
+	// embedded in HTML.
+	EscapeHTMLSafe
+	// EscapeJavaScriptSafe additionally escapes U+2028 and U+2029, which
+	// are valid in JSON strings but end the line in older JavaScript, so the
+	// output can be embedded in a script.
+	EscapeJavaScriptSafe
+	// EscapeASCII escapes every non-ASCII character so the output is pure
+	// ASCII.
+	EscapeASCII
+)
+
+// SetEscapeProfile selects the escaping applied to every key and string
+// value in the output of MarshalJSON. It takes precedence over SetEscapeHTML.
+// Note that json.Marshal escapes HTML characters in the output of any
+// MarshalJSON method, so call MarshalJSON directly or use a json.Encoder
+// with SetEscapeHTML(false) when using a profile that leaves them as is.
+func (o *OrderedMap) SetEscapeProfile(profile EscapeProfile) {
+	o.escapeProfile = profile
+}
+
+const hex = "0123456789abcdef"
+
+// applyEscapeProfile rewrites every string in the JSON document b using the
+// given profile.
+func applyEscapeProfile(b []byte, profile EscapeProfile) ([]byte, error) {
+	dst := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] != '"' {
+			dst = append(dst, b[i])
+			continue
+		}
+		// find the end of the string
+		end := i + 1
+		for ; end < len(b) && b[end] != '"'; end++ {
+			if b[end] == '\\' {
+				end++
+			}
+		}
+		var s string
+		if err := json.Unmarshal(b[i:end+1], &s); err != nil {
+			return nil, err
+		}
+		dst = appendEscapedString(dst, s, profile)
+		i = end
+	}
+	return dst, nil
+}
+
+func appendEscapedString(dst []byte, s string, profile EscapeProfile) []byte {
+	dst = append(dst, '"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			dst = append(dst, '\\', byte(r))
+		case r == '\n':
+			dst = append(dst, '\\', 'n')
+		case r == '\r':
+			dst = append(dst, '\\', 'r')
+		case r == '\t':
+			dst = append(dst, '\\', 't')
+		case r == '\b':
+			dst = append(dst, '\\', 'b')
+		case r == '\f':
+			dst = append(dst, '\\', 'f')
+		case r < 0x20,
+			profile >= EscapeHTMLSafe && profile <= EscapeJavaScriptSafe && (r == '<' || r == '>' || r == '&'),
+			profile == EscapeJavaScriptSafe && (r == '\u2028' || r == '\u2029'),
+			profile == EscapeASCII && r >= utf8.RuneSelf && r <= 0xFFFF:
+			dst = appendUnicodeEscape(dst, r)
+		case profile == EscapeASCII && r > 0xFFFF:
+			// characters outside the basic multilingual plane are written
+			// as a UTF-16 surrogate pair
+			r -= 0x10000
+			dst = appendUnicodeEscape(dst, 0xD800+(r>>10))
+			dst = appendUnicodeEscape(dst, 0xDC00+(r&0x3FF))
+		default:
+			var buf [utf8.UTFMax]byte
+			n := utf8.EncodeRune(buf[:], r)
+			dst = append(dst, buf[:n]...)
+		}
+	}
+	return append(dst, '"')
+}
+
+func appendUnicodeEscape(dst []byte, r rune) []byte {
+	return append(dst, '\\', 'u', hex[r>>12&0xF], hex[r>>8&0xF], hex[r>>4&0xF], hex[r&0xF])
+}
@@ -0,0 +1,45 @@
+package orderedmap
+
+import (
+	"testing"
+)
+
+func TestSetEscapeProfile(t *testing.T) {
+	tests := []struct {
+		profile  EscapeProfile
+		expected string
+	}{
+		{EscapeMinimal, "{\"<k>\":\"a<b>&\\n\\u0001\u2028é😀\"}"},
+		{EscapeHTMLSafe, `{"\u003ck\u003e":"a\u003cb\u003e\u0026\n\u0001` + "\u2028" + `é😀"}`},
+		{EscapeJavaScriptSafe, `{"\u003ck\u003e":"a\u003cb\u003e\u0026\n\u0001\u2028é😀"}`},
+		{EscapeASCII, `{"<k>":"a<b>&\n\u0001\u2028\u00e9\ud83d\ude00"}`},
+	}
+	for _, test := range tests {
+		o := New()
+		o.Set("<k>", "a<b>&\n\x01\u2028é😀")
+		o.SetEscapeProfile(test.profile)
+		b, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatal("MarshalJSON", err)
+		}
+		if string(b) != test.expected {
+			t.Errorf("Escape profile %d\nexpected %s\ngot      %s", test.profile, test.expected, b)
+		}
+	}
+}
+
+func TestSetEscapeProfileNested(t *testing.T) {
+	inner := New()
+	inner.Set("x", []interface{}{"é", struct{ S string }{"ü"}})
+	o := New()
+	o.Set("inner", inner)
+	o.SetEscapeProfile(EscapeASCII)
+	b, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal("MarshalJSON", err)
+	}
+	expected := `{"inner":{"x":["\u00e9",{"S":"\u00fc"}]}}`
+	if string(b) != expected {
+		t.Error("Nested escape profile", string(b))
+	}
+}
@@ -0,0 +1,98 @@
+package orderedmap
+
+// maskNode is a tree of field mask paths. A node without children masks
+// its whole value.
+type maskNode map[string]maskNode
+
+// newMask builds the tree of dotted paths, as accepted by GetDotted.
+func newMask(paths []string) maskNode {
+	root := maskNode{}
+	for _, path := range paths {
+		node := root
+		keys := splitDotted(path)
+		for i, key := range keys {
+			child, exists := node[key]
+			if exists && len(child) == 0 {
+				// a shorter path already masks the whole value
+				break
+			}
+			if i == len(keys)-1 {
+				// masks the whole value, replacing any longer paths
+				node[key] = maskNode{}
+				break
+			}
+			if !exists {
+				child = maskNode{}
+				node[key] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+// ApplyFieldMask removes every value that is not covered by one of paths,
+// in the manner of a protobuf FieldMask, keeping the order of the
+// remaining keys. Each path is a dotted path as accepted by GetDotted, and
+// covers the value at that path and everything nested in it. A path through
+// an array applies to each map in the array.
+func (o *OrderedMap) ApplyFieldMask(paths []string) {
+	o.applyMask(newMask(paths), true)
+}
+
+// RemoveFieldMask removes every value covered by one of paths, the
+// complement of ApplyFieldMask.
+func (o *OrderedMap) RemoveFieldMask(paths []string) {
+	o.applyMask(newMask(paths), false)
+}
+
+// applyMask keeps the values masked by node if keep is set, otherwise it
+// removes them.
+func (o *OrderedMap) applyMask(node maskNode, keep bool) {
+	var remove []string
+	for _, k := range o.keys {
+		child, masked := node[k]
+		switch {
+		case !masked:
+			if keep {
+				remove = append(remove, k)
+			}
+		case len(child) == 0:
+			if !keep {
+				remove = append(remove, k)
+			}
+		default:
+			v, ok := applyMaskValue(o.values[k], child, keep)
+			if ok {
+				o.Set(k, v)
+			} else {
+				remove = append(remove, k)
+			}
+		}
+	}
+	o.DeleteMany(remove...)
+}
+
+// applyMaskValue applies node to v, returning false if v should be removed
+// because it cannot hold the masked paths.
+func applyMaskValue(v interface{}, node maskNode, keep bool) (interface{}, bool) {
+	switch v := v.(type) {
+	case OrderedMap:
+		v.applyMask(node, keep)
+		return v, true
+	case *OrderedMap:
+		if v != nil {
+			v.applyMask(node, keep)
+		}
+		return v, true
+	case []interface{}:
+		for i, elem := range v {
+			elem, ok := applyMaskValue(elem, node, keep)
+			if ok {
+				v[i] = elem
+			}
+		}
+		return v, true
+	}
+	return v, !keep
+}
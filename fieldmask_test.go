@@ -0,0 +1,44 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyFieldMask(t *testing.T) {
+	input := `{"id":1,"name":"n","address":{"city":"c","zip":"z","geo":{"lat":1,"lng":2}},"items":[{"sku":"a","qty":1},{"sku":"b","qty":2}],"note":"x"}`
+	tests := []struct {
+		paths         []string
+		apply, remove string
+	}{
+		{
+			[]string{"name", "address.city", "items.sku"},
+			`{"name":"n","address":{"city":"c"},"items":[{"sku":"a"},{"sku":"b"}]}`,
+			`{"id":1,"address":{"zip":"z","geo":{"lat":1,"lng":2}},"items":[{"qty":1},{"qty":2}],"note":"x"}`,
+		},
+		{
+			[]string{"address.geo.lat", "address", "missing.x"},
+			`{"address":{"city":"c","zip":"z","geo":{"lat":1,"lng":2}}}`,
+			`{"id":1,"name":"n","items":[{"sku":"a","qty":1},{"sku":"b","qty":2}],"note":"x"}`,
+		},
+		{
+			[]string{"note.sub", "id"},
+			`{"id":1}`,
+			`{"name":"n","address":{"city":"c","zip":"z","geo":{"lat":1,"lng":2}},"items":[{"sku":"a","qty":1},{"sku":"b","qty":2}],"note":"x"}`,
+		},
+	}
+	for _, test := range tests {
+		o := mustUnmarshal(t, input)
+		o.ApplyFieldMask(test.paths)
+		b, _ := json.Marshal(o)
+		if string(b) != test.apply {
+			t.Error("ApplyFieldMask", test.paths, string(b))
+		}
+		o = mustUnmarshal(t, input)
+		o.RemoveFieldMask(test.paths)
+		b, _ = json.Marshal(o)
+		if string(b) != test.remove {
+			t.Error("RemoveFieldMask", test.paths, string(b))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package orderedmap
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// FillFromStruct sets the exported fields of the struct v, or the struct v
+// points to, in the map, named as encoding/json names them and skipping
+// empty fields tagged omitempty. A field whose key is already in the map
+// replaces the value in its position if overwrite is set, and is skipped
+// otherwise. A new key is added at the end of the map unless the field has
+// an orderedmap tag naming an anchor key, as in `orderedmap:"after=id"` or
+// `orderedmap:"before=meta"`, in which case it is inserted next to the
+// anchor if the anchor is in the map.
+func (o *OrderedMap) FillFromStruct(v interface{}, overwrite bool) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return errors.New("orderedmap: FillFromStruct needs a struct")
+	}
+	for _, f := range structFields(rv.Type()) {
+		fv, ok := readField(rv, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		key := o.canonicalKey(f.name)
+		if _, exists := o.values[key]; exists {
+			if overwrite {
+				o.Set(key, fv.Interface())
+			}
+			continue
+		}
+		o.Set(key, fv.Interface())
+		if anchor, after, ok := fieldAnchor(f.tag); ok {
+			o.moveNextTo(key, o.canonicalKey(anchor), after)
+		}
+	}
+	return nil
+}
+
+// fieldAnchor parses an orderedmap tag of the form before=key or after=key.
+func fieldAnchor(tag reflect.StructTag) (anchor string, after bool, ok bool) {
+	opt := tag.Get("orderedmap")
+	if strings.HasPrefix(opt, "after=") {
+		return opt[len("after="):], true, true
+	}
+	if strings.HasPrefix(opt, "before=") {
+		return opt[len("before="):], false, true
+	}
+	return "", false, false
+}
+
+// moveNextTo moves key to just before or after anchor. It does nothing if
+// either key is not in the map.
+func (o *OrderedMap) moveNextTo(key, anchor string, after bool) {
+	from, to := indexOfKey(o.keys, key), indexOfKey(o.keys, anchor)
+	if from < 0 || to < 0 || from == to {
+		return
+	}
+	o.touch(key)
+	keys := append(o.keys[:from:from], o.keys[from+1:]...)
+	to = indexOfKey(keys, anchor)
+	if after {
+		to++
+	}
+	keys = append(keys[:to], append([]string{key}, keys[to:]...)...)
+	o.keys = keys
+}
+
+// readField returns the field of v at index, or false if it is inside a
+// nil embedded struct pointer.
+func readField(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmptyValue reports whether v is empty as defined by encoding/json's
+// omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type fillBase struct {
+	Kind string `json:"kind" orderedmap:"before=id"`
+}
+
+type fillTarget struct {
+	*fillBase
+	Name    string `json:"name" orderedmap:"after=id"`
+	Status  string `json:"status"`
+	Count   int    `json:"count,omitempty"`
+	Secret  string `json:"-"`
+	Version int    `json:"version" orderedmap:"after=missing"`
+	ID      int    `json:"id"`
+}
+
+func TestFillFromStruct(t *testing.T) {
+	o := mustUnmarshal(t, `{"id":1,"meta":{"a":1},"status":"old"}`)
+	v := fillTarget{fillBase: &fillBase{"user"}, Name: "n", Status: "new", Secret: "s", Version: 2, ID: 7}
+	if err := o.FillFromStruct(&v, false); err != nil {
+		t.Fatal("FillFromStruct", err)
+	}
+	b, _ := json.Marshal(o)
+	if string(b) != `{"kind":"user","id":1,"name":"n","meta":{"a":1},"status":"old","version":2}` {
+		t.Error("FillFromStruct", string(b))
+	}
+	if err := o.FillFromStruct(v, true); err != nil {
+		t.Fatal("FillFromStruct", err)
+	}
+	b, _ = json.Marshal(o)
+	if string(b) != `{"kind":"user","id":7,"name":"n","meta":{"a":1},"status":"new","version":2}` {
+		t.Error("FillFromStruct with overwrite", string(b))
+	}
+	// nil embedded pointers are skipped
+	o = *New()
+	if err := o.FillFromStruct(fillTarget{Count: 3}, false); err != nil {
+		t.Fatal("FillFromStruct", err)
+	}
+	b, _ = json.Marshal(o)
+	if string(b) != `{"name":"","status":"","count":3,"version":0,"id":0}` {
+		t.Error("FillFromStruct with nil embedded struct", string(b))
+	}
+	if err := o.FillFromStruct(1, false); err == nil {
+		t.Error("FillFromStruct with non-struct did not fail")
+	}
+}
@@ -0,0 +1,61 @@
+package orderedmap
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+)
+
+// OrderFingerprint returns a hash of the sequence of keys in the map,
+// ignoring values, so that a reordering of keys can be detected separately
+// from changes to values. Maps with the same keys in the same order have
+// the same fingerprint.
+func (o *OrderedMap) OrderFingerprint() uint64 {
+	h := fnv.New64a()
+	fingerprintKeys(h, o, false)
+	return h.Sum64()
+}
+
+// DeepOrderFingerprint is like OrderFingerprint, but also includes the key
+// order of nested maps, including maps in arrays, and the positions of the
+// nested maps and arrays in the document.
+func (o *OrderedMap) DeepOrderFingerprint() uint64 {
+	h := fnv.New64a()
+	fingerprintKeys(h, o, true)
+	return h.Sum64()
+}
+
+func fingerprintKeys(h hash.Hash64, o *OrderedMap, deep bool) {
+	keys := o.Keys()
+	writeLength(h, len(keys))
+	for _, k := range keys {
+		writeLength(h, len(k))
+		h.Write([]byte(k))
+		if deep {
+			fingerprintValue(h, o.values[k])
+		}
+	}
+}
+
+func fingerprintValue(h hash.Hash64, v interface{}) {
+	switch v := derefMap(v).(type) {
+	case OrderedMap:
+		h.Write([]byte{'{'})
+		fingerprintKeys(h, &v, true)
+	case []interface{}:
+		h.Write([]byte{'['})
+		writeLength(h, len(v))
+		for _, elem := range v {
+			fingerprintValue(h, elem)
+		}
+	default:
+		h.Write([]byte{'-'})
+	}
+}
+
+// writeLength writes n to h so that the keys hashed after it cannot be
+// confused with differently split keys.
+func writeLength(h hash.Hash64, n int) {
+	var b [binary.MaxVarintLen64]byte
+	h.Write(b[:binary.PutUvarint(b[:], uint64(n))])
+}
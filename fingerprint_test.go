@@ -0,0 +1,27 @@
+package orderedmap
+
+import "testing"
+
+func TestOrderFingerprint(t *testing.T) {
+	a := mustUnmarshal(t, `{"a":1,"b":{"x":1,"y":2},"c":[{"p":1,"q":2}]}`)
+	values := mustUnmarshal(t, `{"a":2,"b":{"x":3,"y":4},"c":[{"p":5,"q":6}]}`)
+	nested := mustUnmarshal(t, `{"a":1,"b":{"y":2,"x":1},"c":[{"p":1,"q":2}]}`)
+	inArray := mustUnmarshal(t, `{"a":1,"b":{"x":1,"y":2},"c":[{"q":2,"p":1}]}`)
+	top := mustUnmarshal(t, `{"b":{"x":1,"y":2},"a":1,"c":[{"p":1,"q":2}]}`)
+	split := mustUnmarshal(t, `{"ab":1,"":{"x":1,"y":2},"c":[{"p":1,"q":2}]}`)
+
+	if a.OrderFingerprint() != values.OrderFingerprint() || a.DeepOrderFingerprint() != values.DeepOrderFingerprint() {
+		t.Error("fingerprint depends on values")
+	}
+	if a.OrderFingerprint() != nested.OrderFingerprint() {
+		t.Error("OrderFingerprint depends on nested order")
+	}
+	for _, o := range []OrderedMap{nested, inArray, top, split} {
+		if a.DeepOrderFingerprint() == o.DeepOrderFingerprint() {
+			t.Error("DeepOrderFingerprint did not change", o.Keys())
+		}
+	}
+	if a.OrderFingerprint() == top.OrderFingerprint() {
+		t.Error("OrderFingerprint did not change")
+	}
+}
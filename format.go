@@ -0,0 +1,487 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Format names a versioned JSON output profile. The bytes produced by a
+// registered format for a given document never change between releases;
+// changes to the output are introduced as new formats instead.
+type Format string
+
+const (
+	// FormatV1 writes keys in map order, compactly, escaping HTML
+	// characters according to SetEscapeHTML. It is the default format.
+	FormatV1 Format = "v1"
+	// FormatCanonical writes keys sorted by byte order at every level,
+	// compactly, without escaping HTML characters. Equal documents always
+	// produce identical bytes regardless of insertion order, which makes it
+	// suitable for hashing and signing.
+	FormatCanonical Format = "canonical"
+)
+
+// A FormatFunc writes the JSON encoding of o to buf.
+type FormatFunc func(buf *bytes.Buffer, o *OrderedMap) error
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[Format]FormatFunc{
+		FormatV1: func(buf *bytes.Buffer, o *OrderedMap) error {
+			e := newEncodeState(buf, !o.noPool)
+			defer e.release()
+			return e.marshalRoot(o)
+		},
+		FormatCanonical: func(buf *bytes.Buffer, o *OrderedMap) error {
+			e := newEncodeState(buf, !o.noPool)
+			defer e.release()
+			e.canonical = true
+			return e.marshalRoot(o)
+		},
+	}
+)
+
+// RegisterFormat makes fn available under the given name for use with
+// SetFormat. It panics if fn is nil or the name is already registered.
+func RegisterFormat(format Format, fn FormatFunc) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if fn == nil {
+		panic("orderedmap: RegisterFormat func is nil")
+	}
+	if _, dup := formats[format]; dup || format == "" {
+		panic(fmt.Sprintf("orderedmap: RegisterFormat called twice for format %q", format))
+	}
+	formats[format] = fn
+}
+
+func lookupFormat(format Format) (FormatFunc, error) {
+	if format == "" {
+		format = FormatV1
+	}
+	formatsMu.RLock()
+	fn, ok := formats[format]
+	formatsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("orderedmap: unknown format %q", format)
+	}
+	return fn, nil
+}
+
+// SetFormat selects the output profile used by MarshalJSON. The format of
+// the outermost map applies to the whole document, including nested maps.
+// Note that json.Marshal escapes HTML characters in the output of any
+// MarshalJSON method, so call MarshalJSON directly or use a json.Encoder
+// with SetEscapeHTML(false) to get the exact bytes of a format.
+func (o *OrderedMap) SetFormat(format Format) {
+	o.format = format
+}
+
+// SetTimeLayout sets the layout used to write time.Time values, as accepted
+// by time.Time.Format. An empty layout, the default, writes times as
+// time.Time.MarshalJSON does, and UnixTimeLayout writes them as numbers of
+// seconds since the Unix epoch. The setting of the outermost map applies to
+// the whole document.
+func (o *OrderedMap) SetTimeLayout(layout string) {
+	o.timeLayout = layout
+}
+
+// SetMarshalStringers sets whether values implementing fmt.Stringer are
+// written as the string returned by their String method. Values that
+// implement json.Marshaler or encoding.TextMarshaler are still written
+// using those. The setting of the outermost map applies to the whole
+// document.
+func (o *OrderedMap) SetMarshalStringers(on bool) {
+	o.stringers = on
+}
+
+// UnsupportedMode selects how MarshalJSON handles values that encoding/json
+// cannot encode, such as channels, funcs and NaN.
+type UnsupportedMode int
+
+const (
+	// UnsupportedError makes MarshalJSON return a *MarshalError. It is the
+	// default.
+	UnsupportedError UnsupportedMode = iota
+	// UnsupportedSkip leaves out keys with unsupported values, and writes
+	// unsupported array elements as null to keep the positions of the
+	// others.
+	UnsupportedSkip
+	// UnsupportedString writes unsupported values as strings formatted by
+	// fmt.Sprint.
+	UnsupportedString
+)
+
+// SetUnsupportedMode sets how values that encoding/json cannot encode are
+// handled. The setting of the outermost map applies to the whole document.
+func (o *OrderedMap) SetUnsupportedMode(mode UnsupportedMode) {
+	o.unsupportedMode = mode
+}
+
+// MarshalError is returned by MarshalJSON when a value cannot be encoded.
+type MarshalError struct {
+	// Path is the JSON Pointer of the value.
+	Path string
+	// Err is the error encoding the value.
+	Err error
+}
+
+func (e *MarshalError) Error() string {
+	return fmt.Sprintf("orderedmap: marshalling %s: %v", e.Path, e.Err)
+}
+
+func (e *MarshalError) Unwrap() error {
+	return e.Err
+}
+
+// errSkipValue is returned by marshalElem for an unsupported value that is
+// left out.
+var errSkipValue = errors.New("orderedmap: skipped unsupported value")
+
+// A NumberFormatter returns the JSON text of the number n at path, which
+// holds the keys and array indices leading to it, or "" to write n as
+// usual. n is a Go integer or float, a json.Number or a big number.
+type NumberFormatter func(path []string, n interface{}) string
+
+// SetNumberFormatter sets a function choosing how numbers are written, eg
+// with a fixed number of decimal places. The text it returns must be a
+// valid JSON number. The setting of the outermost map applies to the whole
+// document.
+func (o *OrderedMap) SetNumberFormatter(f NumberFormatter) {
+	o.numberFormatter = f
+}
+
+// encodeState writes a tree of OrderedMaps, slices and plain values as JSON.
+type encodeState struct {
+	buf        *bytes.Buffer
+	enc        *json.Encoder
+	escapeHTML bool
+	// fixedEscape is set if escapeHTML applies to nested maps regardless of
+	// their own settings
+	fixedEscape bool
+	canonical   bool
+	pooled      bool
+	timeLayout  string
+	stringers   bool
+	unsupported UnsupportedMode
+	// bigNumbers is set if *big.Float values are written as numbers
+	bigNumbers bool
+	// numbers and path are only used if the map has a NumberFormatter
+	numbers NumberFormatter
+	path    []string
+}
+
+var encodeStatePool sync.Pool
+
+// newEncodeState returns an encodeState writing to buf, reusing a released
+// one if pooled is set.
+func newEncodeState(buf *bytes.Buffer, pooled bool) *encodeState {
+	if pooled {
+		if e, ok := encodeStatePool.Get().(*encodeState); ok {
+			e.buf = buf
+			return e
+		}
+	}
+	e := &encodeState{buf: buf, pooled: pooled}
+	// the encoder writes through e so it can be reused with another buffer
+	e.enc = json.NewEncoder(e)
+	return e
+}
+
+func (e *encodeState) Write(p []byte) (int, error) {
+	return e.buf.Write(p)
+}
+
+// release returns e to the pool if it came from newEncodeState with pooled
+// set. e must not be used afterwards.
+func (e *encodeState) release() {
+	if !e.pooled {
+		return
+	}
+	*e = encodeState{enc: e.enc, pooled: true}
+	encodeStatePool.Put(e)
+}
+
+// marshalRoot writes o using its settings for the whole document.
+func (e *encodeState) marshalRoot(o *OrderedMap) error {
+	e.useSettings(o)
+	// the canonical format never escapes HTML
+	if o.escapeHTMLRecursive && !e.canonical {
+		e.escapeHTML, e.fixedEscape = o.escapeHTML, true
+	}
+	return e.marshalMap(o)
+}
+
+// useSettings applies the settings of o that hold for the whole document,
+// other than HTML escaping.
+func (e *encodeState) useSettings(o *OrderedMap) {
+	e.timeLayout = o.timeLayout
+	e.stringers = o.stringers
+	e.numbers = o.numberFormatter
+	e.path = e.path[:0]
+	e.unsupported = o.unsupportedMode
+	e.bigNumbers = o.bigNumbers
+}
+
+func (e *encodeState) marshalMap(o *OrderedMap) error {
+	// a nested map escapes HTML if it or any of its parents does
+	escapeHTML := e.escapeHTML
+	if !e.canonical && !e.fixedEscape {
+		e.escapeHTML = escapeHTML || o.escapeHTML
+	}
+	defer func() { e.escapeHTML = escapeHTML }()
+	keys := o.keys
+	if e.canonical {
+		keys = append([]string(nil), keys...)
+		sort.Strings(keys)
+	}
+	e.buf.WriteByte('{')
+	written := 0
+	for _, k := range keys {
+		start := e.buf.Len()
+		if written > 0 {
+			e.buf.WriteByte(',')
+		}
+		// add key
+		if err := e.encode(k); err != nil {
+			return err
+		}
+		e.buf.WriteByte(':')
+		// add value
+		if err := e.marshalElem(k, o.values[k]); err == errSkipValue {
+			e.buf.Truncate(start)
+			continue
+		} else if err != nil {
+			return err
+		}
+		written++
+	}
+	e.buf.WriteByte('}')
+	return nil
+}
+
+// marshalElem writes v, the value of a key or array index, keeping track
+// of the path if numbers are formatted. It handles unsupported values as
+// set by SetUnsupportedMode, returning errSkipValue if v is to be left out,
+// and adds key to the path of a *MarshalError.
+func (e *encodeState) marshalElem(key string, v interface{}) error {
+	start := e.buf.Len()
+	var err error
+	if e.numbers == nil {
+		err = e.marshalValue(v)
+	} else {
+		e.path = append(e.path, key)
+		err = e.marshalValue(v)
+		e.path = e.path[:len(e.path)-1]
+	}
+	if err == nil {
+		return nil
+	}
+	var me *MarshalError
+	if errors.As(err, &me) {
+		me.Path = "/" + pointerEscape(key) + me.Path
+		return me
+	}
+	if isUnsupported(err) && e.unsupported != UnsupportedError {
+		e.buf.Truncate(start)
+		if e.unsupported == UnsupportedSkip {
+			return errSkipValue
+		}
+		return e.encode(fmt.Sprint(v))
+	}
+	return &MarshalError{Path: "/" + pointerEscape(key), Err: err}
+}
+
+// isUnsupported reports whether err is from encoding/json being unable to
+// encode a value.
+func isUnsupported(err error) bool {
+	var typeErr *json.UnsupportedTypeError
+	var valueErr *json.UnsupportedValueError
+	return errors.As(err, &typeErr) || errors.As(err, &valueErr)
+}
+
+func (e *encodeState) marshalValue(v interface{}) error {
+	switch v := v.(type) {
+	case Computed:
+		computed, err := v()
+		if err != nil {
+			return err
+		}
+		return e.marshalValue(computed)
+	case OrderedMap:
+		return e.marshalMap(&v)
+	case *OrderedMap:
+		if v == nil {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.marshalMap(v)
+	case []interface{}:
+		if v == nil {
+			e.buf.WriteString("null")
+			return nil
+		}
+		e.buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.marshalElem(strconv.Itoa(i), elem); err == errSkipValue {
+				e.buf.WriteString("null")
+			} else if err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+		return nil
+	case []OrderedMap:
+		if v == nil {
+			e.buf.WriteString("null")
+			return nil
+		}
+		e.buf.WriteByte('[')
+		for i := range v {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.marshalElem(strconv.Itoa(i), &v[i]); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+		return nil
+	case map[string]interface{}:
+		if v == nil {
+			e.buf.WriteString("null")
+			return nil
+		}
+		// plain maps are written with sorted keys, as encoding/json does
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		e.buf.WriteByte('{')
+		written := 0
+		for _, k := range keys {
+			start := e.buf.Len()
+			if written > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.encode(k); err != nil {
+				return err
+			}
+			e.buf.WriteByte(':')
+			if err := e.marshalElem(k, v[k]); err == errSkipValue {
+				e.buf.Truncate(start)
+				continue
+			} else if err != nil {
+				return err
+			}
+			written++
+		}
+		e.buf.WriteByte('}')
+		return nil
+	}
+	if e.numbers != nil && isNumber(v) {
+		if s := e.numbers(e.path, v); s != "" {
+			// json.Number checks the syntax
+			return e.encode(json.Number(s))
+		}
+	}
+	if f, ok := v.(*big.Float); ok && f != nil && e.bigNumbers {
+		// big.Float marshals as a string by default
+		if f.IsInf() {
+			return &json.UnsupportedValueError{Value: reflect.ValueOf(f), Str: f.String()}
+		}
+		e.buf.WriteString(f.Text('g', -1))
+		return nil
+	}
+	if t, ok := v.(time.Time); ok && e.timeLayout != "" {
+		if e.timeLayout == UnixTimeLayout {
+			e.buf.WriteString(strconv.FormatInt(t.Unix(), 10))
+			return nil
+		}
+		return e.encode(t.Format(e.timeLayout))
+	}
+	if s, ok := v.(fmt.Stringer); ok && e.stringers && !hasJSONForm(v) {
+		return e.encode(s.String())
+	}
+	if e.canonical {
+		return e.encodeCanonical(v)
+	}
+	return e.encode(v)
+}
+
+// encodeCanonical writes v with the keys of every object in it sorted,
+// including the objects of maps that encoding/json writes in their own
+// order, eg in a []*OrderedMap or a struct field.
+func (e *encodeState) encodeCanonical(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 || b[0] != '{' && b[0] != '[' {
+		return e.encode(v)
+	}
+	// decoded objects are plain maps, which are written with sorted keys,
+	// and json.Number keeps the text of numbers
+	var tree interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		return err
+	}
+	return e.encode(tree)
+}
+
+// isNumber reports whether v is a number that a NumberFormatter is given.
+func isNumber(v interface{}) bool {
+	switch n := v.(type) {
+	case json.Number:
+		return true
+	case *big.Int:
+		return n != nil
+	case *big.Float:
+		return n != nil
+	}
+	if hasJSONForm(v) {
+		return false
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// hasJSONForm reports whether v defines its own JSON or text encoding.
+func hasJSONForm(v interface{}) bool {
+	switch v.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+	return false
+}
+
+// encode writes v using encoding/json.
+func (e *encodeState) encode(v interface{}) error {
+	e.enc.SetEscapeHTML(e.escapeHTML)
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	// drop the newline added by Encode
+	e.buf.Truncate(e.buf.Len() - 1)
+	return nil
+}
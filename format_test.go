@@ -0,0 +1,236 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+const goldenInput = `{
+  "z": "<b>&amp;</b>",
+  "a": [3, 1.5, {"y": null, "x": true}],
+  "m": {"é": "ü", "b": {}, "a": []},
+  "n": -0.000001
+}`
+
+var goldenOutputs = map[Format]string{
+	FormatV1:        `{"z":"\u003cb\u003e\u0026amp;\u003c/b\u003e","a":[3,1.5,{"y":null,"x":true}],"m":{"é":"ü","b":{},"a":[]},"n":-0.000001}`,
+	FormatCanonical: `{"a":[3,1.5,{"x":true,"y":null}],"m":{"a":[],"b":{},"é":"ü"},"n":-0.000001,"z":"<b>&amp;</b>"}`,
+}
+
+func TestFormatGolden(t *testing.T) {
+	for format, expected := range goldenOutputs {
+		o := New()
+		if err := json.Unmarshal([]byte(goldenInput), o); err != nil {
+			t.Fatal("Unmarshal", err)
+		}
+		o.SetFormat(format)
+		b, err := o.MarshalJSON()
+		if err != nil {
+			t.Fatal("MarshalJSON", format, err)
+		}
+		if string(b) != expected {
+			t.Errorf("Format %s output changed\nexpected %s\ngot      %s", format, expected, b)
+		}
+		// json.Marshal must not alter the output
+		b, err = json.Marshal(o)
+		if err != nil {
+			t.Fatal("json.Marshal", format, err)
+		}
+		if format == FormatV1 && string(b) != expected {
+			t.Errorf("json.Marshal %s output changed\nexpected %s\ngot      %s", format, expected, b)
+		}
+	}
+}
+
+func TestFormatCanonicalIgnoresInsertionOrder(t *testing.T) {
+	a := New()
+	a.Set("x", 1)
+	a.Set("y", 2)
+	b := New()
+	b.Set("y", 2)
+	b.Set("x", 1)
+	a.SetFormat(FormatCanonical)
+	b.SetFormat(FormatCanonical)
+	ab, _ := a.MarshalJSON()
+	bb, _ := b.MarshalJSON()
+	if !bytes.Equal(ab, bb) {
+		t.Error("Canonical output depends on insertion order", string(ab), string(bb))
+	}
+}
+
+func TestFormatCanonicalIgnoresEscapeHTMLRecursive(t *testing.T) {
+	inner := New()
+	inner.Set("b", "<b>")
+	o := New()
+	o.Set("a", "&")
+	o.Set("n", *inner)
+	o.SetEscapeHTMLRecursive(true)
+	o.SetFormat(FormatCanonical)
+	b, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal("MarshalJSON", err)
+	}
+	if string(b) != `{"a":"&","n":{"b":"<b>"}}` {
+		t.Error("Canonical output escapes HTML", string(b))
+	}
+}
+
+func TestFormatCanonicalNestedGoValues(t *testing.T) {
+	inner := New()
+	inner.Set("b", 1)
+	inner.Set("a", "<x>")
+	o := New()
+	o.SetFormat(FormatCanonical)
+	o.Set("y", []*OrderedMap{inner})
+	o.Set("m", map[string]*OrderedMap{"k": inner})
+	o.Set("s", struct {
+		Z   int
+		Map *OrderedMap
+		N   json.Number
+	}{1, inner, "12345678901234567890.10"})
+	b, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal("MarshalJSON", err)
+	}
+	expected := `{"m":{"k":{"a":"<x>","b":1}},"s":{"Map":{"a":"<x>","b":1},"N":12345678901234567890.10,"Z":1},"y":[{"a":"<x>","b":1}]}`
+	if string(b) != expected {
+		t.Error("Canonical nested Go values", string(b))
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	custom := Format("test-keys-only")
+	RegisterFormat(custom, func(buf *bytes.Buffer, o *OrderedMap) error {
+		return json.NewEncoder(buf).Encode(o.Keys())
+	})
+	o := New()
+	o.Set("a", 1)
+	o.SetFormat(custom)
+	b, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal("MarshalJSON custom format", err)
+	}
+	if string(b) != "[\"a\"]\n" {
+		t.Error("Custom format output", string(b))
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Registering a format twice should panic")
+			}
+		}()
+		RegisterFormat(FormatV1, func(buf *bytes.Buffer, o *OrderedMap) error { return nil })
+	}()
+	o.SetFormat("unknown")
+	if _, err := o.MarshalJSON(); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+type testStringer int
+
+func (s testStringer) String() string {
+	return fmt.Sprintf("stringer %d", int(s))
+}
+
+func TestSetTimeLayoutAndStringers(t *testing.T) {
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	nested := New()
+	nested.Set("when", when)
+	o := New()
+	o.Set("when", when)
+	o.Set("nested", []interface{}{nested})
+	o.Set("stringer", testStringer(1))
+	o.Set("ip", net.IPv4(127, 0, 0, 1))
+	b, _ := json.Marshal(o)
+	expected := `{"when":"2020-01-02T03:04:05Z","nested":[{"when":"2020-01-02T03:04:05Z"}],"stringer":1,"ip":"127.0.0.1"}`
+	if string(b) != expected {
+		t.Error("Default time and stringer output", string(b))
+	}
+	o.SetTimeLayout("2006-01-02")
+	o.SetMarshalStringers(true)
+	b, _ = json.Marshal(o)
+	expected = `{"when":"2020-01-02","nested":[{"when":"2020-01-02"}],"stringer":"stringer 1","ip":"127.0.0.1"}`
+	if string(b) != expected {
+		t.Error("Time layout and stringer output", string(b))
+	}
+}
+
+func TestNumberFormatter(t *testing.T) {
+	o := mustUnmarshal(t, `{"price":12.5,"qty":3,"lines":[{"price":1}],"id":"7"}`)
+	o.Set("plain", map[string]interface{}{"price": 2})
+	var paths []string
+	o.SetNumberFormatter(func(path []string, n interface{}) string {
+		paths = append(paths, fmt.Sprint(path))
+		if path[len(path)-1] == "price" {
+			f, _ := toFloat64(n)
+			return fmt.Sprintf("%.2f", f)
+		}
+		return ""
+	})
+	b, err := o.MarshalJSON()
+	if err != nil {
+		t.Fatal("MarshalJSON", err)
+	}
+	expected := `{"price":12.50,"qty":3,"lines":[{"price":1.00}],"id":"7","plain":{"price":2.00}}`
+	if string(b) != expected {
+		t.Error("NumberFormatter", string(b))
+	}
+	if fmt.Sprint(paths) != "[[price] [qty] [lines 0 price] [plain price]]" {
+		t.Error("NumberFormatter paths", paths)
+	}
+	o.SetNumberFormatter(func([]string, interface{}) string { return "1,000" })
+	if _, err := o.MarshalJSON(); err == nil {
+		t.Error("NumberFormatter with invalid number did not fail")
+	}
+}
+
+func TestSetUnsupportedMode(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("ch", make(chan int))
+	nested := New()
+	nested.Set("list", []interface{}{1, func() {}, math.NaN()})
+	nested.Set("plain", map[string]interface{}{"f": func() {}, "x": 2})
+	o.Set("n", nested)
+
+	_, err := o.MarshalJSON()
+	var me *MarshalError
+	if !errors.As(err, &me) || me.Path != "/ch" {
+		t.Fatal("MarshalJSON error", err)
+	}
+	var typeErr *json.UnsupportedTypeError
+	if !errors.As(err, &typeErr) {
+		t.Error("MarshalJSON error does not wrap the json error", err)
+	}
+	o.Delete("ch")
+	if _, err := o.MarshalJSON(); err == nil || err.Error() != "orderedmap: marshalling /n/list/1: json: unsupported type: func()" {
+		t.Error("MarshalJSON nested error", err)
+	}
+	o.Set("ch", make(chan int))
+
+	o.SetUnsupportedMode(UnsupportedSkip)
+	b, err := o.MarshalJSON()
+	if err != nil || string(b) != `{"a":1,"n":{"list":[1,null,null],"plain":{"x":2}}}` {
+		t.Error("UnsupportedSkip", string(b), err)
+	}
+	var chunked bytes.Buffer
+	if _, err := o.NewChunkEncoder(1).WriteTo(&chunked); err != nil || chunked.String() != string(b) {
+		t.Error("UnsupportedSkip with ChunkEncoder", chunked.String(), err)
+	}
+
+	o.SetUnsupportedMode(UnsupportedString)
+	nested.Set("list", []interface{}{math.Inf(1)})
+	nested.Delete("plain")
+	o.Set("ch", (chan int)(nil))
+	b, err = o.MarshalJSON()
+	if err != nil || string(b) != `{"a":1,"n":{"list":["+Inf"]},"ch":"\u003cnil\u003e"}` {
+		t.Error("UnsupportedString", string(b), err)
+	}
+}
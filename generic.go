@@ -0,0 +1,315 @@
+//go:build go1.18
+// +build go1.18
+
+package orderedmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// OrderedMapOf is an ordered map with string keys and values of type V. It
+// behaves like OrderedMap without boxing its values in interfaces. Values
+// are marshalled and unmarshalled with encoding/json.
+type OrderedMapOf[V any] struct {
+	keys   []string
+	values map[string]V
+}
+
+// NewOf returns an empty OrderedMapOf.
+func NewOf[V any]() *OrderedMapOf[V] {
+	return &OrderedMapOf[V]{
+		keys:   []string{},
+		values: map[string]V{},
+	}
+}
+
+// Get returns the value of key and whether the key is in the map.
+func (o *OrderedMapOf[V]) Get(key string) (V, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// Set sets the value of key, adding the key to the end of the map if it is
+// not already in it.
+func (o *OrderedMapOf[V]) Set(key string, value V) {
+	if o.values == nil {
+		o.values = map[string]V{}
+	}
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Delete removes key from the map.
+func (o *OrderedMapOf[V]) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	o.keys = removeKey(o.keys, key)
+	delete(o.values, key)
+}
+
+// Keys returns the keys of the map in order.
+func (o *OrderedMapOf[V]) Keys() []string {
+	return o.keys
+}
+
+// Len returns the number of keys in the map.
+func (o *OrderedMapOf[V]) Len() int {
+	return len(o.keys)
+}
+
+func (o OrderedMapOf[V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		vb, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object into the map, replacing its
+// contents. A duplicate key takes the position of its last occurrence, as
+// in OrderedMap.
+func (o *OrderedMapOf[V]) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("orderedmap: cannot unmarshal %v into OrderedMapOf", token)
+	}
+	o.keys = []string{}
+	o.values = map[string]V{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+		var v V
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		if _, exists := o.values[key]; exists {
+			o.keys = removeKey(o.keys, key)
+		}
+		o.keys = append(o.keys, key)
+		o.values[key] = v
+	}
+	_, err = dec.Token() // '}'
+	return err
+}
+
+// OrderedMapKV is an ordered map with keys of type K and values of type V.
+// Keys are converted to and from JSON object member names as encoding/json
+// converts map keys: string types are used as they are, other types that
+// implement encoding.TextMarshaler and encoding.TextUnmarshaler use those
+// methods, and integers are formatted in decimal. Float and bool keys are
+// also supported, formatted by fmt and parsed by strconv.
+type OrderedMapKV[K comparable, V any] struct {
+	keys   []K
+	values map[K]V
+}
+
+// NewKV returns an empty OrderedMapKV.
+func NewKV[K comparable, V any]() *OrderedMapKV[K, V] {
+	return &OrderedMapKV[K, V]{
+		keys:   []K{},
+		values: map[K]V{},
+	}
+}
+
+// Get returns the value of key and whether the key is in the map.
+func (o *OrderedMapKV[K, V]) Get(key K) (V, bool) {
+	v, ok := o.values[key]
+	return v, ok
+}
+
+// Set sets the value of key, adding the key to the end of the map if it is
+// not already in it.
+func (o *OrderedMapKV[K, V]) Set(key K, value V) {
+	if o.values == nil {
+		o.values = map[K]V{}
+	}
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Delete removes key from the map.
+func (o *OrderedMapKV[K, V]) Delete(key K) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+	delete(o.values, key)
+}
+
+// Keys returns the keys of the map in order.
+func (o *OrderedMapKV[K, V]) Keys() []K {
+	return o.keys
+}
+
+// Len returns the number of keys in the map.
+func (o *OrderedMapKV[K, V]) Len() int {
+	return len(o.keys)
+}
+
+func (o OrderedMapKV[K, V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		s, err := formatKey(k)
+		if err != nil {
+			return nil, err
+		}
+		kb, err := json.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		vb, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object into the map, replacing its
+// contents. A duplicate key takes the position of its last occurrence, as
+// in OrderedMap.
+func (o *OrderedMapKV[K, V]) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("orderedmap: cannot unmarshal %v into OrderedMapKV", token)
+	}
+	o.keys = []K{}
+	o.values = map[K]V{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, err := parseKey[K](token.(string))
+		if err != nil {
+			return err
+		}
+		var v V
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		o.Delete(key)
+		o.Set(key, v)
+	}
+	_, err = dec.Token() // '}'
+	return err
+}
+
+// formatKey returns the JSON object key for k, following encoding/json:
+// string kinds are used as they are, then encoding.TextMarshaler is
+// preferred, with a nil pointer written as "". Other keys are formatted
+// by kind, the inverse of parseKey, so a String method is never used.
+func formatKey[K comparable](k K) (string, error) {
+	v := reflect.ValueOf(&k).Elem()
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	if tm, ok := any(k).(encoding.TextMarshaler); ok {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return "", nil
+		}
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	}
+	return "", fmt.Errorf("orderedmap: cannot marshal key of type %T", k)
+}
+
+// parseKey parses a JSON object key into a K, preferring
+// encoding.TextUnmarshaler as encoding/json does.
+func parseKey[K comparable](s string) (K, error) {
+	var k K
+	if u, ok := any(&k).(encoding.TextUnmarshaler); ok {
+		return k, u.UnmarshalText([]byte(s))
+	}
+	v := reflect.ValueOf(&k).Elem()
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	default:
+		return k, fmt.Errorf("orderedmap: cannot unmarshal key into %T", k)
+	}
+	if err != nil {
+		return k, fmt.Errorf("orderedmap: invalid key %q for %T", s, k)
+	}
+	return k, nil
+}
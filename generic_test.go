@@ -0,0 +1,186 @@
+//go:build go1.18
+// +build go1.18
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type genericNode struct {
+	Name string `json:"name"`
+}
+
+func TestOrderedMapOf(t *testing.T) {
+	o := NewOf[*genericNode]()
+	o.Set("b", &genericNode{"B"})
+	o.Set("a", &genericNode{"A"})
+	o.Set("c", &genericNode{"C"})
+	o.Delete("a")
+	if n, ok := o.Get("b"); !ok || n.Name != "B" {
+		t.Error("Get", n, ok)
+	}
+	if _, ok := o.Get("a"); ok {
+		t.Error("Get deleted key")
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"b":{"name":"B"},"c":{"name":"C"}}` {
+		t.Error("Marshal", string(b))
+	}
+	var decoded OrderedMapOf[int]
+	if err := json.Unmarshal([]byte(`{"z":1,"y":2,"z":3,"x":4}`), &decoded); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if !reflect.DeepEqual(decoded.Keys(), []string{"y", "z", "x"}) || decoded.values["z"] != 3 || decoded.Len() != 3 {
+		t.Error("Unmarshal", decoded.Keys(), decoded.values)
+	}
+	if err := json.Unmarshal([]byte(`{"a":"no"}`), &decoded); err == nil {
+		t.Error("Unmarshal wrong value type did not fail")
+	}
+}
+
+type genericID struct{ n int }
+
+func (id genericID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("id-%d", id.n)), nil
+}
+
+func (id *genericID) UnmarshalText(b []byte) error {
+	_, err := fmt.Sscanf(string(b), "id-%d", &id.n)
+	return err
+}
+
+func TestOrderedMapKV(t *testing.T) {
+	o := NewKV[int, string]()
+	o.Set(3, "c")
+	o.Set(1, "a")
+	o.Set(2, "b")
+	o.Delete(1)
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"3":"c","2":"b"}` {
+		t.Error("Marshal", string(b))
+	}
+	var decoded OrderedMapKV[int, string]
+	if err := json.Unmarshal([]byte(`{"10":"x","-2":"y","10":"z"}`), &decoded); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if !reflect.DeepEqual(decoded.Keys(), []int{-2, 10}) {
+		t.Error("Unmarshal", decoded.Keys())
+	}
+	if v, _ := decoded.Get(10); v != "z" || decoded.Len() != 2 {
+		t.Error("Unmarshal duplicate", v)
+	}
+	if err := json.Unmarshal([]byte(`{"x":"y"}`), &decoded); err == nil {
+		t.Error("Unmarshal invalid int key did not fail")
+	}
+
+	ids := NewKV[genericID, int]()
+	ids.Set(genericID{7}, 1)
+	ids.Set(genericID{5}, 2)
+	b, _ = json.Marshal(ids)
+	if string(b) != `{"id-7":1,"id-5":2}` {
+		t.Error("Marshal TextMarshaler keys", string(b))
+	}
+	var decodedIDs OrderedMapKV[genericID, int]
+	if err := json.Unmarshal(b, &decodedIDs); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if !reflect.DeepEqual(decodedIDs.Keys(), []genericID{{7}, {5}}) {
+		t.Error("Unmarshal TextUnmarshaler keys", decodedIDs.Keys())
+	}
+}
+
+// genericColor is an integer key whose String method must not be used as
+// its JSON key.
+type genericColor int
+
+func (c genericColor) String() string {
+	return "color"
+}
+
+func TestOrderedMapKVKindKeys(t *testing.T) {
+	colors := NewKV[genericColor, int]()
+	colors.Set(2, 1)
+	colors.Set(-1, 2)
+	b, err := json.Marshal(colors)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"2":1,"-1":2}` {
+		t.Error("Marshal Stringer int keys", string(b))
+	}
+	var decoded OrderedMapKV[genericColor, int]
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if !reflect.DeepEqual(decoded.Keys(), []genericColor{2, -1}) {
+		t.Error("Unmarshal Stringer int keys", decoded.Keys())
+	}
+
+	floats := NewKV[float32, bool]()
+	floats.Set(0.1, true)
+	bools := NewKV[bool, uint8]()
+	bools.Set(true, 255)
+	b, _ = json.Marshal(floats)
+	if string(b) != `{"0.1":true}` {
+		t.Error("Marshal float keys", string(b))
+	}
+	b, _ = json.Marshal(bools)
+	if string(b) != `{"true":255}` {
+		t.Error("Marshal bool keys", string(b))
+	}
+	structs := NewKV[struct{ N int }, int]()
+	structs.Set(struct{ N int }{1}, 1)
+	if _, err := json.Marshal(structs); err == nil {
+		t.Error("Marshal struct keys did not fail")
+	}
+}
+
+// genericName is a string key whose MarshalText is ignored when it is
+// marshalled, as documented by encoding/json, but whose UnmarshalText is
+// used when it is unmarshalled.
+type genericName string
+
+func (n genericName) MarshalText() ([]byte, error) {
+	return []byte("marshalled"), nil
+}
+
+func (n *genericName) UnmarshalText(b []byte) error {
+	*n = genericName("parsed-" + string(b))
+	return nil
+}
+
+func TestOrderedMapKVTextKeys(t *testing.T) {
+	o := NewKV[genericName, int]()
+	o.Set("a", 1)
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"a":1}` {
+		t.Error("Marshal string key", string(b))
+	}
+	var decoded OrderedMapKV[genericName, int]
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if _, ok := decoded.Get("parsed-a"); !ok {
+		t.Error("Unmarshal TextUnmarshaler key", decoded.Keys())
+	}
+	ptrs := NewKV[*genericID, int]()
+	ptrs.Set(nil, 1)
+	ptrs.Set(&genericID{2}, 2)
+	b, _ = json.Marshal(ptrs)
+	if string(b) != `{"":1,"id-2":2}` {
+		t.Error("Marshal pointer keys", string(b))
+	}
+}
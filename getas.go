@@ -0,0 +1,25 @@
+//go:build go1.18
+// +build go1.18
+
+package orderedmap
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GetAs returns the value of key in o as a T. It returns an error naming the
+// key and the actual type if the key is not in the map or its value is not
+// a T.
+func GetAs[T any](o OrderedMap, key string) (T, error) {
+	var zero T
+	v, ok := o.Get(key)
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("orderedmap: value of key %q is %T, not %v", key, v, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return t, nil
+}
@@ -0,0 +1,38 @@
+//go:build go1.18
+// +build go1.18
+
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGetAs(t *testing.T) {
+	o := New()
+	if err := json.Unmarshal([]byte(`{"s":"x","n":1,"m":{"a":1}}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	s, err := GetAs[string](*o, "s")
+	if err != nil || s != "x" {
+		t.Error("GetAs string", s, err)
+	}
+	m, err := GetAs[OrderedMap](*o, "m")
+	if err != nil || m.Keys()[0] != "a" {
+		t.Error("GetAs OrderedMap", m, err)
+	}
+	_, err = GetAs[string](*o, "n")
+	if err == nil || !strings.Contains(err.Error(), `"n" is float64, not string`) {
+		t.Error("GetAs wrong type", err)
+	}
+	_, err = GetAs[int](*o, "missing")
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Error("GetAs missing key", err)
+	}
+	_, err = GetAs[json.Marshaler](*o, "n")
+	if err == nil || !strings.Contains(err.Error(), "not json.Marshaler") {
+		t.Error("GetAs interface type", err)
+	}
+}
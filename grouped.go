@@ -0,0 +1,56 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// UnmarshalGrouped decodes b, a JSON array of objects, into the map with
+// the objects grouped by the value of their groupKey member. Each key of the
+// map is a group value, in the order the values are first seen, and each
+// value is a []interface{} of the objects in that group, in array order.
+// String group values are used as they are and other values are converted
+// to their JSON encoding. The key transform of o, if any, is applied to the
+// objects, to groupKey and to the group values. Any existing entries in the
+// map are removed, unless an error is returned, in which case o is left
+// unchanged.
+func (o *OrderedMap) UnmarshalGrouped(b []byte, groupKey string) error {
+	var rows []OrderedMap
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return err
+	}
+	groupKey = o.canonicalKey(groupKey)
+	var names []string
+	groups := map[string][]interface{}{}
+	for i, row := range rows {
+		if o.keyTransform != nil {
+			row.keyTransform = o.keyTransform
+			row.applyKeyTransform()
+		}
+		v, ok := row.values[groupKey]
+		if !ok {
+			return fmt.Errorf("%w: %q in array element %d", ErrKeyNotFound, groupKey, i)
+		}
+		group, ok := v.(string)
+		if !ok {
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			group = string(encoded)
+		}
+		group = o.canonicalKey(group)
+		if _, exists := groups[group]; !exists {
+			names = append(names, group)
+		}
+		groups[group] = append(groups[group], row)
+	}
+	o.DeleteMany(o.KeysCopy()...)
+	if o.values == nil {
+		o.values = map[string]interface{}{}
+	}
+	for _, group := range names {
+		o.Set(group, groups[group])
+	}
+	return nil
+}
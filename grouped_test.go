@@ -0,0 +1,48 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalGrouped(t *testing.T) {
+	s := `[
+  {"team": "red", "name": "a"},
+  {"team": "blue", "name": "b"},
+  {"team": "red", "name": "c"},
+  {"team": 7, "name": "d"}
+]`
+	o := New()
+	o.Set("stale", true)
+	if err := o.UnmarshalGrouped([]byte(s), "team"); err != nil {
+		t.Fatal("UnmarshalGrouped", err)
+	}
+	b, _ := json.Marshal(o)
+	expected := `{"red":[{"team":"red","name":"a"},{"team":"red","name":"c"}],"blue":[{"team":"blue","name":"b"}],"7":[{"team":7,"name":"d"}]}`
+	if string(b) != expected {
+		t.Error("UnmarshalGrouped", string(b), "!=", expected)
+	}
+	err := o.UnmarshalGrouped([]byte(`[{"team":"red"},{"name":"x"}]`), "team")
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Error("UnmarshalGrouped missing group key", err)
+	}
+	if err := o.UnmarshalGrouped([]byte(`{"team":"red"}`), "team"); err == nil {
+		t.Error("UnmarshalGrouped of an object should fail")
+	}
+	// a failed call leaves the map as it was
+	if b, _ := json.Marshal(o); string(b) != expected {
+		t.Error("UnmarshalGrouped failure changed the map", string(b))
+	}
+
+	o = New()
+	o.SetKeyTransform(strings.ToLower)
+	if err := o.UnmarshalGrouped([]byte(`[{"Team":"Red","n":1},{"team":"red","n":2}]`), "TEAM"); err != nil {
+		t.Fatal("UnmarshalGrouped with key transform", err)
+	}
+	b, _ = json.Marshal(o)
+	if string(b) != `{"red":[{"team":"Red","n":1},{"team":"red","n":2}]}` {
+		t.Error("UnmarshalGrouped with key transform", string(b))
+	}
+}
@@ -0,0 +1,543 @@
+package orderedmap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query returns the values matching a JSONPath expression, in document
+// order. It supports a subset of JSONPath:
+//
+//	$               the root map
+//	.key, ['key']   a member of a map, with ["key"] and ['a','b'] also accepted
+//	.*, [*]         every member of a map or element of an array
+//	[0], [-1], [0,2] elements of an array, negative indices counting from the end
+//	[1:3], [:-1]    a slice of an array
+//	..key, ..*      recursive descent, matching at any depth
+//	[?(expr)]       members or elements for which expr is true
+//
+// A filter expression compares paths relative to the current value, such
+// as @.price or @['a b'][0], with numbers, quoted strings, true, false,
+// null or other paths, using ==, !=, <, <=, > and >=. A path on its own
+// tests for existence. Comparisons can be combined with && and ||, where
+// && binds more tightly.
+func (o *OrderedMap) Query(path string) ([]interface{}, error) {
+	selectors, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []interface{}{o}
+	for _, s := range selectors {
+		var next []interface{}
+		for _, node := range nodes {
+			if s.recursive {
+				for _, d := range descendants(node, nil) {
+					next = s.apply(d, next)
+				}
+			} else {
+				next = s.apply(node, next)
+			}
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// jsonPathSelector is one step of a JSONPath expression.
+type jsonPathSelector struct {
+	recursive bool
+	wildcard  bool
+	names     []string
+	indices   []int
+	slice     *jsonPathSlice
+	filter    jsonPathExpr
+}
+
+type jsonPathSlice struct {
+	start, end *int
+}
+
+// apply appends the values node selects to out.
+func (s *jsonPathSelector) apply(node interface{}, out []interface{}) []interface{} {
+	m, isMap := asMap(node)
+	arr, isArray := node.([]interface{})
+	switch {
+	case s.wildcard || s.filter != nil:
+		var children []interface{}
+		if isMap {
+			for _, k := range m.keys {
+				children = append(children, m.values[k])
+			}
+		} else if isArray {
+			children = arr
+		}
+		for _, c := range children {
+			if s.filter == nil || s.filter.test(c) {
+				out = append(out, c)
+			}
+		}
+	case s.names != nil:
+		if isMap {
+			for _, name := range s.names {
+				if v, ok := m.Get(name); ok {
+					out = append(out, v)
+				}
+			}
+		}
+	case s.indices != nil:
+		if isArray {
+			for _, i := range s.indices {
+				if i < 0 {
+					i += len(arr)
+				}
+				if i >= 0 && i < len(arr) {
+					out = append(out, arr[i])
+				}
+			}
+		}
+	case s.slice != nil:
+		if isArray {
+			start, end := 0, len(arr)
+			if s.slice.start != nil {
+				start = clampIndex(*s.slice.start, len(arr))
+			}
+			if s.slice.end != nil {
+				end = clampIndex(*s.slice.end, len(arr))
+			}
+			for i := start; i < end; i++ {
+				out = append(out, arr[i])
+			}
+		}
+	}
+	return out
+}
+
+// clampIndex resolves a possibly negative slice bound for an array of
+// length n.
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+// descendants appends v and all the values nested in it to out, in
+// document order.
+func descendants(v interface{}, out []interface{}) []interface{} {
+	out = append(out, v)
+	if m, ok := asMap(v); ok {
+		for _, k := range m.keys {
+			out = descendants(m.values[k], out)
+		}
+	} else if arr, ok := v.([]interface{}); ok {
+		for _, elem := range arr {
+			out = descendants(elem, out)
+		}
+	}
+	return out
+}
+
+// jsonPathParser reads a JSONPath expression or filter.
+type jsonPathParser struct {
+	s string
+	i int
+}
+
+func (p *jsonPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("orderedmap: invalid JSONPath at offset %d: %s", p.i, fmt.Sprintf(format, args...))
+}
+
+func (p *jsonPathParser) skipSpace() {
+	for p.i < len(p.s) && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+// consume skips spaces and then prefix if it is next.
+func (p *jsonPathParser) consume(prefix string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.s[p.i:], prefix) {
+		p.i += len(prefix)
+		return true
+	}
+	return false
+}
+
+func parseJSONPath(path string) ([]*jsonPathSelector, error) {
+	p := &jsonPathParser{s: path}
+	if !p.consume("$") {
+		return nil, p.errorf("expected $")
+	}
+	var selectors []*jsonPathSelector
+	for p.i < len(p.s) {
+		s, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, s)
+	}
+	return selectors, nil
+}
+
+// selector reads one step: .name, .*, ..name, ..*, ..[...] or [...].
+func (p *jsonPathParser) selector() (*jsonPathSelector, error) {
+	s := &jsonPathSelector{}
+	switch {
+	case strings.HasPrefix(p.s[p.i:], ".."):
+		p.i += 2
+		s.recursive = true
+		if p.i < len(p.s) && p.s[p.i] == '[' {
+			return s, p.bracket(s)
+		}
+	case p.s[p.i] == '.':
+		p.i++
+	case p.s[p.i] == '[':
+		return s, p.bracket(s)
+	default:
+		return nil, p.errorf("expected . or [")
+	}
+	if p.i < len(p.s) && p.s[p.i] == '*' {
+		p.i++
+		s.wildcard = true
+		return s, nil
+	}
+	name := p.name()
+	if name == "" {
+		return nil, p.errorf("expected a name")
+	}
+	s.names = []string{name}
+	return s, nil
+}
+
+// name reads a member name after a dot.
+func (p *jsonPathParser) name() string {
+	start := p.i
+	for p.i < len(p.s) && !strings.ContainsRune(".[]()=!<>&| ", rune(p.s[p.i])) {
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+// bracket reads a selector in brackets.
+func (p *jsonPathParser) bracket(s *jsonPathSelector) error {
+	p.i++ // '['
+	p.skipSpace()
+	switch {
+	case p.consume("*"):
+		s.wildcard = true
+	case p.consume("?"):
+		if !p.consume("(") {
+			return p.errorf("expected ( after ?")
+		}
+		expr, err := p.or()
+		if err != nil {
+			return err
+		}
+		if !p.consume(")") {
+			return p.errorf("expected )")
+		}
+		s.filter = expr
+	case p.i < len(p.s) && (p.s[p.i] == '\'' || p.s[p.i] == '"'):
+		for {
+			name, err := p.quoted()
+			if err != nil {
+				return err
+			}
+			s.names = append(s.names, name)
+			if !p.consume(",") {
+				break
+			}
+			p.skipSpace()
+		}
+	default:
+		if err := p.indices(s); err != nil {
+			return err
+		}
+	}
+	if !p.consume("]") {
+		return p.errorf("expected ]")
+	}
+	return nil
+}
+
+// indices reads array indices or a slice.
+func (p *jsonPathParser) indices(s *jsonPathSelector) error {
+	start := p.i
+	for p.i < len(p.s) && p.s[p.i] != ']' {
+		p.i++
+	}
+	spec := strings.Replace(p.s[start:p.i], " ", "", -1)
+	if strings.Contains(spec, ":") {
+		bounds := strings.Split(spec, ":")
+		if len(bounds) != 2 {
+			return p.errorf("slice steps are not supported")
+		}
+		s.slice = &jsonPathSlice{}
+		for j, bound := range bounds {
+			if bound == "" {
+				continue
+			}
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return p.errorf("invalid slice bound %q", bound)
+			}
+			if j == 0 {
+				s.slice.start = &n
+			} else {
+				s.slice.end = &n
+			}
+		}
+		return nil
+	}
+	for _, index := range strings.Split(spec, ",") {
+		n, err := strconv.Atoi(index)
+		if err != nil {
+			return p.errorf("invalid index %q", index)
+		}
+		s.indices = append(s.indices, n)
+	}
+	return nil
+}
+
+// quoted reads a string in single or double quotes, with backslash
+// escapes.
+func (p *jsonPathParser) quoted() (string, error) {
+	quote := p.s[p.i]
+	var b strings.Builder
+	for p.i++; p.i < len(p.s); p.i++ {
+		c := p.s[p.i]
+		switch {
+		case c == '\\' && p.i+1 < len(p.s):
+			p.i++
+			b.WriteByte(p.s[p.i])
+		case c == quote:
+			p.i++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// jsonPathExpr is a filter expression.
+type jsonPathExpr interface {
+	test(v interface{}) bool
+}
+
+type jsonPathOr []jsonPathExpr
+
+func (e jsonPathOr) test(v interface{}) bool {
+	for _, sub := range e {
+		if sub.test(v) {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonPathAnd []jsonPathExpr
+
+func (e jsonPathAnd) test(v interface{}) bool {
+	for _, sub := range e {
+		if !sub.test(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonPathOperand is a literal or a path relative to the current value.
+type jsonPathOperand struct {
+	literal interface{}
+	path    []*jsonPathSelector
+	isPath  bool
+}
+
+func (o jsonPathOperand) value(v interface{}) (interface{}, bool) {
+	if !o.isPath {
+		return o.literal, true
+	}
+	nodes := []interface{}{v}
+	for _, s := range o.path {
+		var next []interface{}
+		for _, node := range nodes {
+			next = s.apply(node, next)
+		}
+		nodes = next
+	}
+	if len(nodes) == 0 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+type jsonPathComparison struct {
+	left, right jsonPathOperand
+	op          string
+}
+
+func (e jsonPathComparison) test(v interface{}) bool {
+	a, ok := e.left.value(v)
+	if e.op == "" || !ok {
+		return ok
+	}
+	b, ok := e.right.value(v)
+	if !ok {
+		return false
+	}
+	c, comparable := compareJSONValues(a, b)
+	switch e.op {
+	case "==":
+		return comparable && c == 0
+	case "!=":
+		return !comparable || c != 0
+	case "<":
+		return comparable && c < 0
+	case "<=":
+		return comparable && c <= 0
+	case ">":
+		return comparable && c > 0
+	case ">=":
+		return comparable && c >= 0
+	}
+	return false
+}
+
+// compareJSONValues compares numbers, strings, bools and nulls, reporting
+// false if a and b cannot be compared. Only numbers and strings are
+// ordered; bools and nulls compare as equal or not.
+func compareJSONValues(a, b interface{}) (int, bool) {
+	if fa, ok := toFloat64(a); ok {
+		fb, ok := toFloat64(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+	switch a := a.(type) {
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a, b), true
+	case bool, nil:
+		if a == b {
+			return 0, true
+		}
+		switch b.(type) {
+		case bool, nil:
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+func (p *jsonPathParser) or() (jsonPathExpr, error) {
+	var or jsonPathOr
+	for {
+		and, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, and)
+		if !p.consume("||") {
+			break
+		}
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *jsonPathParser) and() (jsonPathExpr, error) {
+	var and jsonPathAnd
+	for {
+		cmp, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, cmp)
+		if !p.consume("&&") {
+			break
+		}
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *jsonPathParser) comparison() (jsonPathExpr, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			right, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return jsonPathComparison{left, right, op}, nil
+		}
+	}
+	if !left.isPath {
+		return nil, p.errorf("expected a comparison")
+	}
+	return jsonPathComparison{left: left}, nil
+}
+
+func (p *jsonPathParser) operand() (jsonPathOperand, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return jsonPathOperand{}, p.errorf("expected an operand")
+	}
+	switch c := p.s[p.i]; {
+	case c == '@':
+		p.i++
+		op := jsonPathOperand{isPath: true}
+		for p.i < len(p.s) && (p.s[p.i] == '.' || p.s[p.i] == '[') {
+			s, err := p.selector()
+			if err != nil {
+				return op, err
+			}
+			op.path = append(op.path, s)
+		}
+		return op, nil
+	case c == '\'' || c == '"':
+		s, err := p.quoted()
+		return jsonPathOperand{literal: s}, err
+	case p.consume("true"):
+		return jsonPathOperand{literal: true}, nil
+	case p.consume("false"):
+		return jsonPathOperand{literal: false}, nil
+	case p.consume("null"):
+		return jsonPathOperand{literal: nil}, nil
+	}
+	start := p.i
+	for p.i < len(p.s) && strings.ContainsRune("+-.0123456789eE", rune(p.s[p.i])) {
+		p.i++
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.i], 64)
+	if err != nil {
+		p.i = start
+		return jsonPathOperand{}, p.errorf("expected an operand")
+	}
+	return jsonPathOperand{literal: f}, nil
+}
@@ -0,0 +1,54 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const jsonPathStore = `{"store":{
+	"book":[
+		{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},
+		{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},
+		{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},
+		{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}
+	],
+	"bicycle":{"color":"red","price":19.95}
+}}`
+
+func TestQuery(t *testing.T) {
+	o := mustUnmarshal(t, jsonPathStore)
+	tests := map[string]string{
+		`$.store.book[*].author`:         `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`,
+		`$..author`:                      `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`,
+		`$.store.*`:                      `[[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}],{"color":"red","price":19.95}]`,
+		`$.store..price`:                 `[8.95,12.99,8.99,22.99,19.95]`,
+		`$..book[2].title`:               `["Moby Dick"]`,
+		`$..book[-1].title`:              `["The Lord of the Rings"]`,
+		`$..book[0,1].title`:             `["Sayings of the Century","Sword of Honour"]`,
+		`$..book[:2].title`:              `["Sayings of the Century","Sword of Honour"]`,
+		`$..book[-2:].title`:             `["Moby Dick","The Lord of the Rings"]`,
+		`$..book[?(@.isbn)].title`:       `["Moby Dick","The Lord of the Rings"]`,
+		`$..book[?(@.price < 10)].title`: `["Sayings of the Century","Moby Dick"]`,
+		`$..book[?(@.category == 'fiction' && @.price > 20)].title`:  `["The Lord of the Rings"]`,
+		`$..book[?(@.price > 20 || @.author == "Nigel Rees")].price`: `[8.95,22.99]`,
+		`$['store']['bicycle']['color','price']`:                     `["red",19.95]`,
+		`$.store.bicycle.missing`:                                    `null`,
+		`$`:                                                          `[{"store":{"book":[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3","price":8.99},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","isbn":"0-395-19395-8","price":22.99}],"bicycle":{"color":"red","price":19.95}}}]`,
+	}
+	for path, expected := range tests {
+		values, err := o.Query(path)
+		if err != nil {
+			t.Error("Query", path, err)
+			continue
+		}
+		b, _ := json.Marshal(values)
+		if string(b) != expected {
+			t.Error("Query", path, string(b))
+		}
+	}
+	for _, path := range []string{"", "store", "$.", "$[", "$[?(@.a ==)]", "$[1:2:3]", "$['a]", "$[x]", "$[?(1)]"} {
+		if _, err := o.Query(path); err == nil {
+			t.Error("Query did not fail", path)
+		}
+	}
+}
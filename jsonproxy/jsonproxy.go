@@ -0,0 +1,86 @@
+// Package jsonproxy rewrites JSON object responses passing through a reverse
+// proxy without disturbing the order of their keys.
+package jsonproxy
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/orderedmap"
+)
+
+// ModifyFunc changes the decoded body of an upstream response in place. The
+// response is provided for its status and headers; its body must not be
+// read. The body is decoded with SetBigNumbers, so numbers that a float64
+// cannot hold exactly are *big.Int or *big.Float values.
+type ModifyFunc func(resp *http.Response, body *orderedmap.OrderedMap) error
+
+// New returns a reverse proxy to target that passes the body of every JSON
+// object response through fn.
+func New(target *url.URL, fn ModifyFunc) *httputil.ReverseProxy {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ModifyResponse = ModifyResponse(fn)
+	return proxy
+}
+
+// ModifyResponse returns a func for httputil.ReverseProxy.ModifyResponse that
+// decodes JSON object responses into an OrderedMap, calls fn, and writes the
+// result back with the original key order. Keys added by fn are written
+// after the upstream keys. Numbers keep their exact value, though not
+// always their spelling, eg 1.10 is written as 1.1. Responses that are not
+// uncompressed JSON objects are passed through unchanged.
+func ModifyResponse(fn ModifyFunc) func(*http.Response) error {
+	return func(resp *http.Response) error {
+		if !isJSON(resp.Header.Get("Content-Type")) {
+			return nil
+		}
+		if enc := resp.Header.Get("Content-Encoding"); enc != "" && enc != "identity" {
+			return nil
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+			setBody(resp, body)
+			return nil
+		}
+		o := orderedmap.New()
+		// keep strings and numbers as the upstream sent them
+		o.SetEscapeHTML(false)
+		o.SetBigNumbers(true)
+		if err := o.UnmarshalJSON(body); err != nil {
+			return err
+		}
+		if err := fn(resp, o); err != nil {
+			return err
+		}
+		b, err := o.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		setBody(resp, b)
+		return nil
+	}
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func setBody(resp *http.Response, body []byte) {
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
@@ -0,0 +1,58 @@
+package jsonproxy
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/iancoleman/orderedmap"
+)
+
+func TestNew(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text" {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(`{"z":1,"a":2}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if r.URL.Path == "/numbers" {
+			w.Write([]byte(`{"id":12345678901234567890,"big":9007199254740993,"price":1.10,"pi":3.14159265358979323846}`))
+			return
+		}
+		w.Write([]byte(`{"z":1,"secret":"<x>","m":{"y":1,"b":2},"a":2}`))
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL)
+	proxy := httptest.NewServer(New(target, func(resp *http.Response, body *orderedmap.OrderedMap) error {
+		body.Delete("secret")
+		body.Set("proxied", true)
+		return nil
+	}))
+	defer proxy.Close()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/", `{"z":1,"m":{"y":1,"b":2},"a":2,"proxied":true}`},
+		{"/text", `{"z":1,"a":2}`},
+		{"/numbers", `{"id":12345678901234567890,"big":9007199254740993,"price":1.1,"pi":3.14159265358979323846,"proxied":true}`},
+	}
+	for _, test := range tests {
+		resp, err := http.Get(proxy.URL + test.path)
+		if err != nil {
+			t.Fatal("Get", err)
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(b) != test.expected {
+			t.Error("Proxied body for", test.path, string(b), "!=", test.expected)
+		}
+		if resp.ContentLength != int64(len(test.expected)) {
+			t.Error("Content length for", test.path, resp.ContentLength)
+		}
+	}
+}
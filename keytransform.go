@@ -0,0 +1,65 @@
+package orderedmap
+
+// SetKeyTransform sets a func that maps keys onto their canonical form, eg
+// strings.ToLower or strings.TrimSpace. It is applied to the keys passed to
+// Get, Set, Append, SetMany, Delete, DeleteMany, RenameKey, Pick, Omit and
+// the prefix methods, to the keys of the second map of Union, Intersect,
+// Difference and MergeNumeric, to GroupBy and AggregateBy names, to
+// MemoryStorage keys and to the keys decoded by UnmarshalJSON, so keys that
+// differ only in ways fn removes refer to the same entry. Keys already in
+// the map are not changed. When decoded keys collide, the last value wins
+// and takes the position of the last occurrence, as for duplicate keys. The
+// setting is inherited by nested maps. Pass nil to remove the transform.
+func (o *OrderedMap) SetKeyTransform(fn func(key string) string) {
+	o.keyTransform = fn
+}
+
+// canonicalKey returns key transformed by the key transform, if any.
+func (o *OrderedMap) canonicalKey(key string) string {
+	if o == nil || o.keyTransform == nil {
+		return key
+	}
+	return o.keyTransform(key)
+}
+
+// applyKeyTransform replaces the keys of o and its nested maps with their
+// canonical form.
+func (o *OrderedMap) applyKeyTransform() {
+	keys := make([]string, 0, len(o.keys))
+	values := make(map[string]interface{}, len(o.values))
+	for _, k := range o.keys {
+		v := o.values[k]
+		switch m := v.(type) {
+		case OrderedMap:
+			m.applyKeyTransform()
+			v = m
+		case []interface{}:
+			applyKeyTransformSlice(m)
+		}
+		ck := o.canonicalKey(k)
+		if _, exists := values[ck]; exists {
+			keys = removeKey(keys, ck)
+		}
+		keys = append(keys, ck)
+		values[ck] = v
+	}
+	o.keys, o.values = keys, values
+	for k, raw := range o.raw {
+		if ck := o.canonicalKey(k); ck != k {
+			delete(o.raw, k)
+			o.raw[ck] = raw
+		}
+	}
+}
+
+func applyKeyTransformSlice(s []interface{}) {
+	for i, v := range s {
+		switch v := v.(type) {
+		case OrderedMap:
+			v.applyKeyTransform()
+			s[i] = v
+		case []interface{}:
+			applyKeyTransformSlice(v)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetKeyTransform(t *testing.T) {
+	o := New()
+	o.SetKeyTransform(func(key string) string {
+		return strings.ToLower(strings.TrimSpace(key))
+	})
+	err := json.Unmarshal([]byte(`{" Name ":"a","Age":1,"nested":{"X":1,"x":2},"name":"b","list":[{"Y":1}]}`), o)
+	if err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	b, _ := json.Marshal(o)
+	expected := `{"age":1,"nested":{"x":2},"name":"b","list":[{"y":1}]}`
+	if string(b) != expected {
+		t.Error("SetKeyTransform decode", string(b), "!=", expected)
+	}
+	if v, ok := o.Get("NAME"); !ok || v != "b" {
+		t.Error("SetKeyTransform Get", v, ok)
+	}
+	o.Set(" AGE", 2)
+	o.Delete("Name")
+	if err := o.RenameKey("LIST", "Items"); err != nil {
+		t.Error("RenameKey", err)
+	}
+	if !reflect.DeepEqual(o.Keys(), []string{"age", "nested", "items"}) {
+		t.Error("SetKeyTransform Keys", o.Keys())
+	}
+	if v, _ := o.Get("age"); v != 2 {
+		t.Error("SetKeyTransform Set", v)
+	}
+}
+
+func TestKeyTransformDerivedMaps(t *testing.T) {
+	o := New()
+	o.SetKeyTransform(strings.ToLower)
+	o.Set("Name", "x")
+	o.Set("AWS.Region", "eu")
+	o.Set("Age", 1)
+	other := New()
+	other.Set("NAME", "y")
+	other.Set("Extra", true)
+	tests := []struct {
+		name     string
+		m        OrderedMap
+		expected string
+	}{
+		{"Pick", o.Pick("NAME"), `{"name":"x"}`},
+		{"Omit", o.Omit("NAME", "AGE"), `{"aws.region":"eu"}`},
+		{"PickPrefix", o.PickPrefix("AWS."), `{"aws.region":"eu"}`},
+		{"GroupBy", o.GroupBy(func(p *Pair) string { return "G" }), `{"g":{"name":"x","aws.region":"eu","age":1}}`},
+		{"Union", Union(*o, *other), `{"name":"x","aws.region":"eu","age":1,"extra":true}`},
+		{"Intersect", Intersect(*o, *other), `{"name":"x"}`},
+		{"Difference", Difference(*o, *other), `{"aws.region":"eu","age":1}`},
+	}
+	for _, test := range tests {
+		if b, _ := json.Marshal(test.m); string(b) != test.expected {
+			t.Error(test.name, string(b))
+		}
+	}
+	if keys := o.KeysWithPrefix("AWS."); !reflect.DeepEqual(keys, []string{"aws.region"}) {
+		t.Error("KeysWithPrefix", keys)
+	}
+	if v, ok, _ := NewMemoryStorage(o).Load("NAME"); !ok || v != "x" {
+		t.Error("MemoryStorage Load", v, ok)
+	}
+}
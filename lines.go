@@ -0,0 +1,59 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// LinesWriter writes maps as JSON Lines with a consistent key order. The
+// first line is a header holding the keys as a JSON array, and every
+// following line is an object with exactly those keys in that order. Keys
+// missing from a map are written as null and keys not in the header are
+// dropped.
+type LinesWriter struct {
+	w             io.Writer
+	keys          []string
+	headerWritten bool
+}
+
+// NewLinesWriter returns a LinesWriter writing to w with the given keys. If
+// no keys are given, the keys of the first map written are used.
+func NewLinesWriter(w io.Writer, keys ...string) *LinesWriter {
+	return &LinesWriter{w: w, keys: keys}
+}
+
+// Keys returns the keys of the header, which are nil until the first map is
+// written if none were given to NewLinesWriter.
+func (lw *LinesWriter) Keys() []string {
+	return lw.keys
+}
+
+// Write writes o as one line, preceded by the header if this is the first
+// line.
+func (lw *LinesWriter) Write(o *OrderedMap) error {
+	if !lw.headerWritten {
+		if lw.keys == nil {
+			lw.keys = o.KeysCopy()
+		}
+		header, err := json.Marshal(lw.keys)
+		if err != nil {
+			return err
+		}
+		if _, err := lw.w.Write(append(header, '\n')); err != nil {
+			return err
+		}
+		lw.headerWritten = true
+	}
+	record := o.subMap(func(string) bool { return false })
+	for _, k := range lw.keys {
+		v := o.values[k]
+		record.keys = append(record.keys, k)
+		record.values[k] = v
+	}
+	b, err := record.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = lw.w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,40 @@
+package orderedmap
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLinesWriter(t *testing.T) {
+	var buf bytes.Buffer
+	lw := NewLinesWriter(&buf)
+	records := []string{
+		`{"b":1,"a":"x"}`,
+		`{"a":"y","c":true}`,
+		`{"a":"z","b":{"n":[1,2]}}`,
+	}
+	for _, s := range records {
+		o := mustUnmarshal(t, s)
+		if err := lw.Write(&o); err != nil {
+			t.Fatal("Write", err)
+		}
+	}
+	expected := `["b","a"]
+{"b":1,"a":"x"}
+{"b":null,"a":"y"}
+{"b":{"n":[1,2]},"a":"z"}
+`
+	if buf.String() != expected {
+		t.Error("LinesWriter", buf.String())
+	}
+	buf.Reset()
+	lw = NewLinesWriter(&buf, "c", "a")
+	o := mustUnmarshal(t, records[1])
+	if err := lw.Write(&o); err != nil {
+		t.Fatal("Write", err)
+	}
+	expected = "[\"c\",\"a\"]\n{\"c\":true,\"a\":\"y\"}\n"
+	if buf.String() != expected {
+		t.Error("LinesWriter with keys", buf.String())
+	}
+}
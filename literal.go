@@ -0,0 +1,90 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Of returns a new map containing the given keys and values, which alternate
+// as in Of("a", 1, "b", 2). It panics if a key is not a string or the last
+// key has no value.
+func Of(keysAndValues ...interface{}) *OrderedMap {
+	if len(keysAndValues)%2 != 0 {
+		panic("orderedmap: Of called with an odd number of arguments")
+	}
+	o := New()
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			panic(fmt.Sprintf("orderedmap: Of called with %T key", keysAndValues[i]))
+		}
+		o.Set(key, keysAndValues[i+1])
+	}
+	return o
+}
+
+// ToGoLiteral returns Go source that builds the map with Of, eg to check a
+// captured payload into a test as code. Numbers are written with their
+// types, so that float64(1) does not become the int 1.
+func (o *OrderedMap) ToGoLiteral() string {
+	if o == nil {
+		return "orderedmap.New()"
+	}
+	var buf bytes.Buffer
+	writeGoLiteral(&buf, *o, 0)
+	return buf.String()
+}
+
+func writeGoLiteral(buf *bytes.Buffer, v interface{}, indent int) {
+	switch v := derefMap(v).(type) {
+	case OrderedMap:
+		if len(v.keys) == 0 {
+			buf.WriteString("orderedmap.New()")
+			return
+		}
+		buf.WriteString("orderedmap.Of(\n")
+		for _, k := range v.keys {
+			writeIndent(buf, indent+1)
+			buf.WriteString(strconv.Quote(k))
+			buf.WriteString(", ")
+			writeGoLiteral(buf, v.values[k], indent+1)
+			buf.WriteString(",\n")
+		}
+		writeIndent(buf, indent)
+		buf.WriteString(")")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]interface{}{}")
+			return
+		}
+		buf.WriteString("[]interface{}{\n")
+		for _, e := range v {
+			writeIndent(buf, indent+1)
+			writeGoLiteral(buf, e, indent+1)
+			buf.WriteString(",\n")
+		}
+		writeIndent(buf, indent)
+		buf.WriteString("}")
+	case nil:
+		buf.WriteString("nil")
+	case string:
+		buf.WriteString(strconv.Quote(v))
+	case float64:
+		buf.WriteString("float64(")
+		buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		buf.WriteString(")")
+	case json.Number:
+		buf.WriteString("json.Number(")
+		buf.WriteString(strconv.Quote(string(v)))
+		buf.WriteString(")")
+	default:
+		fmt.Fprintf(buf, "%#v", v)
+	}
+}
+
+func writeIndent(buf *bytes.Buffer, indent int) {
+	buf.WriteString(strings.Repeat("\t", indent))
+}
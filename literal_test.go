@@ -0,0 +1,61 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	o := Of("b", 1, "a", Of("c", "d"), "e", []interface{}{true, nil})
+	b, _ := json.Marshal(o)
+	if string(b) != `{"b":1,"a":{"c":"d"},"e":[true,null]}` {
+		t.Error("Of", string(b))
+	}
+	for _, args := range [][]interface{}{{"a"}, {1, 2}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Of did not panic", args)
+				}
+			}()
+			Of(args...)
+		}()
+	}
+}
+
+func TestToGoLiteral(t *testing.T) {
+	o := mustUnmarshal(t, `{"b":1.5,"a":{"c":"d\n","e":{}},"f":[true,null,[]],"g":2}`)
+	expected := `orderedmap.Of(
+	"b", float64(1.5),
+	"a", orderedmap.Of(
+		"c", "d\n",
+		"e", orderedmap.New(),
+	),
+	"f", []interface{}{
+		true,
+		nil,
+		[]interface{}{},
+	},
+	"g", float64(2),
+)`
+	if s := o.ToGoLiteral(); s != expected {
+		t.Error("ToGoLiteral", s)
+	}
+	// the literal builds an equal map
+	built := Of(
+		"b", float64(1.5),
+		"a", Of(
+			"c", "d\n",
+			"e", New(),
+		),
+		"f", []interface{}{
+			true,
+			nil,
+			[]interface{}{},
+		},
+		"g", float64(2),
+	)
+	if !valuesEqual(o, built, true) {
+		t.Error("ToGoLiteral result is not equal")
+	}
+}
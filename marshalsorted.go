@@ -0,0 +1,154 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// MarshalStructsSorted returns the JSON encoding of v as json.Marshal does,
+// except that the keys of every Go map in v are written in the order given
+// by less, or in byte order if less is nil. Struct fields are written in
+// declaration order and OrderedMaps in their own order, so the output for a
+// whole graph of values is deterministic. Values implementing json.Marshaler
+// or encoding.TextMarshaler are written using those. v must not contain
+// cycles.
+func MarshalStructsSorted(v interface{}, less func(a, b string) bool) ([]byte, error) {
+	if less == nil {
+		less = func(a, b string) bool { return a < b }
+	}
+	tree, err := sortedTree(reflect.ValueOf(v), less)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	e := newEncodeState(&buf, true)
+	defer e.release()
+	e.escapeHTML = true
+	if err := e.marshalValue(tree); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sortedTree converts v into OrderedMaps, slices and values that
+// encoding/json writes as v would be written, with map keys sorted by less.
+func sortedTree(v reflect.Value, less func(a, b string) bool) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Type() == orderedMapType || v.Type() == reflect.PtrTo(orderedMapType) {
+		m, ok := asMap(v.Interface())
+		if !ok {
+			return nil, nil
+		}
+		c := m.subMap(func(string) bool { return true })
+		for k, elem := range c.values {
+			tree, err := sortedTree(reflect.ValueOf(elem), less)
+			if err != nil {
+				return nil, err
+			}
+			c.values[k] = tree
+		}
+		return c, nil
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() {
+		if pt := v.Addr().Type(); pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType) {
+			return v.Addr().Interface(), nil
+		}
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface(), nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return sortedTree(v.Elem(), less)
+	case reflect.Struct:
+		o := New()
+		for _, f := range structFields(v.Type()) {
+			fv, ok := readField(v, f.index)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			tree, err := sortedTree(fv, less)
+			if err != nil {
+				return nil, err
+			}
+			o.Set(f.name, tree)
+		}
+		return *o, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		o := New()
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := sortedKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			tree, err := sortedTree(iter.Value(), less)
+			if err != nil {
+				return nil, err
+			}
+			o.Set(key, tree)
+		}
+		sort.Slice(o.keys, func(i, j int) bool { return less(o.keys[i], o.keys[j]) })
+		return *o, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// written as base64
+			return v.Interface(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			tree, err := sortedTree(v.Index(i), less)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = tree
+		}
+		return s, nil
+	}
+	return v.Interface(), nil
+}
+
+// sortedKey returns the JSON object key for the map key k, as encoding/json
+// does.
+func sortedKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("orderedmap: unsupported map key type %s", k.Type())
+}
@@ -0,0 +1,62 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type sortedInner struct {
+	Z     int            `json:"z"`
+	A     map[string]int `json:"a"`
+	Empty string         `json:"empty,omitempty"`
+}
+
+func TestMarshalStructsSorted(t *testing.T) {
+	o := New()
+	o.Set("y", map[string]bool{"b": true, "a": false})
+	o.Set("x", 1)
+	v := struct {
+		Inner  sortedInner
+		Many   []map[int]string
+		Map    *OrderedMap
+		Time   time.Time
+		Bytes  []byte
+		Nil    map[string]int
+		Nested map[string]interface{}
+	}{
+		Inner:  sortedInner{Z: 1, A: map[string]int{"b": 2, "a": 1, "c": 3}},
+		Many:   []map[int]string{{10: "ten", 9: "nine"}},
+		Map:    o,
+		Time:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Bytes:  []byte("hi"),
+		Nested: map[string]interface{}{"q": map[string]int{"m": 1, "n": 2}, "p": "<"},
+	}
+	b, err := MarshalStructsSorted(v, nil)
+	if err != nil {
+		t.Fatal("MarshalStructsSorted", err)
+	}
+	expected := `{"Inner":{"z":1,"a":{"a":1,"b":2,"c":3}},"Many":[{"10":"ten","9":"nine"}],` +
+		`"Map":{"y":{"a":false,"b":true},"x":1},"Time":"2020-01-02T03:04:05Z","Bytes":"aGk=","Nil":null,` +
+		`"Nested":{"p":"\u003c","q":{"m":1,"n":2}}}`
+	if string(b) != expected {
+		t.Error("MarshalStructsSorted", string(b))
+	}
+	// the output is valid JSON as json.Marshal would write it, apart from key order
+	var got, want interface{}
+	json.Unmarshal(b, &got)
+	stdlib, _ := json.Marshal(v)
+	json.Unmarshal(stdlib, &want)
+	if !valuesEqual(got, want, false) {
+		t.Error("MarshalStructsSorted differs from json.Marshal", string(stdlib))
+	}
+	b, err = MarshalStructsSorted(map[string]int{"a": 1, "bb": 2, "ccc": 3}, func(a, b string) bool {
+		return len(a) > len(b)
+	})
+	if err != nil || string(b) != `{"ccc":3,"bb":2,"a":1}` {
+		t.Error("MarshalStructsSorted with less", string(b), err)
+	}
+	if _, err := MarshalStructsSorted(map[float64]int{1: 1}, nil); err == nil {
+		t.Error("MarshalStructsSorted with float keys did not fail")
+	}
+}
@@ -0,0 +1,327 @@
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Provenance records where a merged value came from.
+type Provenance struct {
+	// Source is the name of the map the value came from.
+	Source string
+	// Index is the position of the key in that map.
+	Index int
+}
+
+// SetSourceName names the map for provenance tracking. When any of the maps
+// given to a merge has a name, the merged map records the provenance of
+// each of its keys, which can be retrieved with Provenance.
+func (o *OrderedMap) SetSourceName(name string) {
+	o.sourceName = name
+}
+
+// Provenance returns the map and position that the value of key was taken
+// from by the merge that produced o. It returns false if provenance was not
+// tracked or key has been set since the merge.
+func (o *OrderedMap) Provenance(key string) (Provenance, bool) {
+	if o == nil {
+		return Provenance{}, false
+	}
+	p, ok := o.provenance[key]
+	return p, ok
+}
+
+// recordProvenance records that the value of key in o came from source,
+// using name if source has no name of its own.
+func (o *OrderedMap) recordProvenance(key string, source *OrderedMap, name string) {
+	if o.provenance == nil {
+		return
+	}
+	if source.sourceName != "" {
+		name = source.sourceName
+	}
+	o.provenance[key] = Provenance{name, indexOfKey(source.keys, key)}
+}
+
+// Merge sets the keys of other in o. Keys that are not in o are added at
+// the end in the order of other. For keys in both maps, the value is
+// chosen by resolve, which is given the values in o and other, or is the
+// value in other if resolve is nil. If other has a source name, the
+// provenance of the keys it sets is recorded; other keys keep theirs.
+func (o *OrderedMap) Merge(other OrderedMap, resolve func(key string, a, b interface{}) interface{}) {
+	for i, k := range other.keys {
+		v := other.values[k]
+		ck := o.canonicalKey(k)
+		if existing, ok := o.values[ck]; ok && resolve != nil {
+			v = resolve(k, existing, v)
+		}
+		o.Set(k, v)
+		if other.sourceName != "" {
+			if o.provenance == nil {
+				o.provenance = map[string]Provenance{}
+			}
+			o.provenance[ck] = Provenance{other.sourceName, i}
+		}
+	}
+}
+
+// DeepMerge merges other into o as Merge does, except that where both maps
+// have a nested map under the same key, the nested map of other is merged
+// into that of o recursively rather than replacing it. Keys keep their
+// position in o and new keys are added at the end. Maps and arrays taken
+// from other are copied, so later merges into o do not change other.
+// Provenance is recorded as by Merge, and in nested maps with the source
+// name of other unless they have their own.
+func (o *OrderedMap) DeepMerge(other OrderedMap) {
+	o.Merge(other.CloneMapped(nil), deepMergeResolver(false, other.sourceName))
+}
+
+// DeepMergeConcat merges other into o as DeepMerge does, and also
+// concatenates arrays under the same key, appending the elements from
+// other.
+func (o *OrderedMap) DeepMergeConcat(other OrderedMap) {
+	o.Merge(other.CloneMapped(nil), deepMergeResolver(true, other.sourceName))
+}
+
+// deepMergeResolver returns the resolver for DeepMerge from a map named
+// source.
+func deepMergeResolver(concat bool, source string) func(key string, a, b interface{}) interface{} {
+	return func(key string, a, b interface{}) interface{} {
+		if bm, ok := asMap(b); ok {
+			// name the nested map so its merge is tracked too
+			nested := *bm
+			if nested.sourceName == "" {
+				nested.sourceName = source
+			}
+			resolve := deepMergeResolver(concat, nested.sourceName)
+			switch am := a.(type) {
+			case OrderedMap:
+				am.Merge(nested, resolve)
+				return am
+			case *OrderedMap:
+				if am != nil {
+					am.Merge(nested, resolve)
+					return am
+				}
+			}
+		}
+		if as, ok := a.([]interface{}); ok && concat {
+			if bs, ok := b.([]interface{}); ok {
+				return append(as[:len(as):len(as)], bs...)
+			}
+		}
+		return b
+	}
+}
+
+// ApplyMergePatch applies patch to o as a JSON Merge Patch, as described
+// in RFC 7396: a null in patch removes the key, an object is merged into
+// the nested map under the same key, replacing any other value, and any
+// other value replaces the value of the key. Keys keep their position in o
+// and new keys are added at the end in the order of patch. Null members
+// recorded by decoding patch with NullRecord remove their keys too.
+func (o *OrderedMap) ApplyMergePatch(patch OrderedMap) error {
+	o.applyMergePatch(&patch)
+	return nil
+}
+
+func (o *OrderedMap) applyMergePatch(patch *OrderedMap) {
+	for _, k := range patch.nulls {
+		o.Delete(k)
+	}
+	for _, k := range patch.keys {
+		v := patch.values[k]
+		if v == nil {
+			o.Delete(k)
+			continue
+		}
+		pm, ok := asMap(v)
+		if !ok {
+			o.Set(k, v)
+			continue
+		}
+		switch target := o.values[o.canonicalKey(k)].(type) {
+		case *OrderedMap:
+			if target != nil {
+				target.applyMergePatch(pm)
+				continue
+			}
+		case OrderedMap:
+			target.applyMergePatch(pm)
+			o.Set(k, target)
+			continue
+		}
+		// patch an empty map so that the nulls in the patch are dropped
+		target := o.subMap(func(string) bool { return false })
+		target.applyMergePatch(pm)
+		o.Set(k, target)
+	}
+}
+
+// ErrConflict is returned by Merge3 when the same value was changed
+// differently on both sides.
+var ErrConflict = errors.New("orderedmap: merge conflict")
+
+// Conflict is a value changed differently on both sides of a three-way
+// merge.
+type Conflict struct {
+	// Path is the keys of the value, from the outermost map.
+	Path []string
+	// Base, Ours and Theirs are the values on each side. A value that is
+	// not present on a side is nil.
+	Base, Ours, Theirs interface{}
+}
+
+// Merge3 merges the changes made to base in ours and in theirs, as git does
+// for files. Keys changed on only one side take that side's change, keys
+// changed the same way on both sides take the change, and nested maps
+// changed on both sides are merged recursively. Any other change made on
+// both sides is reported as a conflict and keeps the value from ours, and
+// Merge3 then also returns an error wrapping ErrConflict that lists the
+// conflicting paths, so that conflicts are not ignored by accident.
+//
+// The result has the key order of ours, unless only theirs reordered the
+// keys of base, in which case it has the order of theirs. Keys added by the
+// other side are placed after the key that precedes them on that side.
+func Merge3(base, ours, theirs OrderedMap) (OrderedMap, []Conflict, error) {
+	var conflicts []Conflict
+	merged := merge3(nil, &base, &ours, &theirs, &conflicts)
+	if len(conflicts) == 0 {
+		return merged, nil, nil
+	}
+	paths := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		paths[i] = strings.Join(c.Path, ".")
+	}
+	return merged, conflicts, fmt.Errorf("%w: %s", ErrConflict, strings.Join(paths, ", "))
+}
+
+func merge3(path []string, base, ours, theirs *OrderedMap, conflicts *[]Conflict) OrderedMap {
+	merged := ours.subMap(func(string) bool { return false })
+	if base.sourceName != "" || ours.sourceName != "" || theirs.sourceName != "" {
+		merged.provenance = map[string]Provenance{}
+	}
+	values := map[string]interface{}{}
+	keep := func(key string) {
+		b, inBase := base.values[key]
+		o, inOurs := ours.values[key]
+		t, inTheirs := theirs.values[key]
+		switch {
+		case inOurs == inTheirs && valuesEqual(o, t, false):
+			if inOurs {
+				values[key] = o
+				merged.recordProvenance(key, ours, "ours")
+			}
+		case inOurs == inBase && valuesEqual(o, b, false):
+			if inTheirs {
+				values[key] = t
+				merged.recordProvenance(key, theirs, "theirs")
+			}
+		case inTheirs == inBase && valuesEqual(t, b, false):
+			if inOurs {
+				values[key] = o
+				merged.recordProvenance(key, ours, "ours")
+			}
+		default:
+			keyPath := append(path[:len(path):len(path)], key)
+			bm, bok := derefMap(b).(OrderedMap)
+			om, ook := derefMap(o).(OrderedMap)
+			tm, tok := derefMap(t).(OrderedMap)
+			if bok && ook && tok {
+				if merged.provenance != nil {
+					// name the nested maps so their merge is tracked too
+					bm.sourceName = provenanceName(base, "base")
+					om.sourceName = provenanceName(ours, "ours")
+					tm.sourceName = provenanceName(theirs, "theirs")
+				}
+				values[key] = merge3(keyPath, &bm, &om, &tm, conflicts)
+				merged.recordProvenance(key, ours, "ours")
+				return
+			}
+			*conflicts = append(*conflicts, Conflict{keyPath, b, o, t})
+			if inOurs {
+				values[key] = o
+				merged.recordProvenance(key, ours, "ours")
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for _, keys := range [][]string{base.keys, ours.keys, theirs.keys} {
+		for _, k := range keys {
+			if !seen[k] {
+				seen[k] = true
+				keep(k)
+			}
+		}
+	}
+	primary, secondary := ours, theirs
+	if sameKeyOrder(base, ours) && !sameKeyOrder(base, theirs) {
+		primary, secondary = theirs, ours
+	}
+	for _, k := range primary.keys {
+		if _, ok := values[k]; ok {
+			merged.keys = append(merged.keys, k)
+		}
+	}
+	// place the keys only on the secondary side after their predecessor
+	for i, k := range secondary.keys {
+		if _, ok := values[k]; !ok || containsKey(merged.keys, k) {
+			continue
+		}
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if p := indexOfKey(merged.keys, secondary.keys[j]); p >= 0 {
+				at = p + 1
+				break
+			}
+		}
+		merged.keys = append(merged.keys, "")
+		copy(merged.keys[at+1:], merged.keys[at:])
+		merged.keys[at] = k
+	}
+	merged.values = values
+	return merged
+}
+
+// provenanceName returns the source name of o, or name if it has none.
+func provenanceName(o *OrderedMap, name string) string {
+	if o.sourceName != "" {
+		return o.sourceName
+	}
+	return name
+}
+
+// sameKeyOrder reports whether the keys in both a and b are in the same
+// relative order in each.
+func sameKeyOrder(a, b *OrderedMap) bool {
+	var common []string
+	for _, k := range a.keys {
+		if _, ok := b.values[k]; ok {
+			common = append(common, k)
+		}
+	}
+	i := 0
+	for _, k := range b.keys {
+		if _, ok := a.values[k]; ok {
+			if common[i] != k {
+				return false
+			}
+			i++
+		}
+	}
+	return true
+}
+
+func indexOfKey(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsKey(keys []string, key string) bool {
+	return indexOfKey(keys, key) >= 0
+}
@@ -0,0 +1,277 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustUnmarshal(t *testing.T, s string) OrderedMap {
+	t.Helper()
+	o := New()
+	if err := json.Unmarshal([]byte(s), o); err != nil {
+		t.Fatal("Unmarshal", s, err)
+	}
+	return *o
+}
+
+func TestMerge(t *testing.T) {
+	o := mustUnmarshal(t, `{"port":80,"hosts":["a"],"debug":false}`)
+	other := mustUnmarshal(t, `{"tls":true,"hosts":["b"],"port":8080,"name":"x"}`)
+	var conflicts []string
+	o.Merge(other, func(key string, a, b interface{}) interface{} {
+		conflicts = append(conflicts, key)
+		if as, ok := a.([]interface{}); ok {
+			return append(as, b.([]interface{})...)
+		}
+		return b
+	})
+	b, _ := json.Marshal(o)
+	if string(b) != `{"port":8080,"hosts":["a","b"],"debug":false,"tls":true,"name":"x"}` {
+		t.Error("Merge", string(b))
+	}
+	if !reflect.DeepEqual(conflicts, []string{"hosts", "port"}) {
+		t.Error("Merge conflicts", conflicts)
+	}
+	o.Merge(mustUnmarshal(t, `{"debug":true,"z":1}`), nil)
+	b, _ = json.Marshal(o)
+	if string(b) != `{"port":8080,"hosts":["a","b"],"debug":true,"tls":true,"name":"x","z":1}` {
+		t.Error("Merge without resolver", string(b))
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	base := `{"server":{"host":"a","tls":{"cert":"c"},"ports":[80]},"debug":false}`
+	overlay := mustUnmarshal(t, `{"server":{"ports":[443],"tls":{"key":"k"},"timeout":5},"debug":true,"extra":{}}`)
+	o := mustUnmarshal(t, base)
+	o.DeepMerge(overlay)
+	b, _ := json.Marshal(o)
+	expected := `{"server":{"host":"a","tls":{"cert":"c","key":"k"},"ports":[443],"timeout":5},"debug":true,"extra":{}}`
+	if string(b) != expected {
+		t.Error("DeepMerge", string(b))
+	}
+	o = mustUnmarshal(t, base)
+	o.DeepMergeConcat(overlay)
+	b, _ = json.Marshal(o)
+	expected = `{"server":{"host":"a","tls":{"cert":"c","key":"k"},"ports":[80,443],"timeout":5},"debug":true,"extra":{}}`
+	if string(b) != expected {
+		t.Error("DeepMergeConcat", string(b))
+	}
+	// a map replaces a value that is not a map and vice versa
+	o = mustUnmarshal(t, `{"a":1,"b":{"x":1}}`)
+	o.DeepMerge(mustUnmarshal(t, `{"a":{"y":2},"b":3}`))
+	b, _ = json.Marshal(o)
+	if string(b) != `{"a":{"y":2},"b":3}` {
+		t.Error("DeepMerge with differing types", string(b))
+	}
+	// pointers to maps are merged in place
+	inner := New()
+	inner.Set("x", 1)
+	o = *New()
+	o.Set("p", inner)
+	o.DeepMerge(mustUnmarshal(t, `{"p":{"y":2}}`))
+	if !reflect.DeepEqual(inner.Keys(), []string{"x", "y"}) {
+		t.Error("DeepMerge pointer", inner.Keys())
+	}
+}
+
+func TestDeepMergeKeepsSources(t *testing.T) {
+	defaults := mustUnmarshal(t, `{"db":{"host":"localhost","port":5432},"tags":["a"]}`)
+	user := mustUnmarshal(t, `{"db":{"host":"prod"},"tags":["b"]}`)
+	extra := mustUnmarshal(t, `{"db":{"user":"x"}}`)
+	cfg := New()
+	cfg.DeepMergeConcat(defaults)
+	cfg.DeepMergeConcat(user)
+	cfg.DeepMerge(extra)
+	b, _ := json.Marshal(cfg)
+	if string(b) != `{"db":{"host":"prod","port":5432,"user":"x"},"tags":["a","b"]}` {
+		t.Error("DeepMerge", string(b))
+	}
+	for s, m := range map[string]OrderedMap{
+		`{"db":{"host":"localhost","port":5432},"tags":["a"]}`: defaults,
+		`{"db":{"host":"prod"},"tags":["b"]}`:                  user,
+		`{"db":{"user":"x"}}`:                                  extra,
+	} {
+		if b, _ := json.Marshal(m); string(b) != s {
+			t.Error("DeepMerge changed a source", string(b), "!=", s)
+		}
+	}
+}
+
+func TestMergeProvenance(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":{"x":1},"c":3}`)
+	defaults := mustUnmarshal(t, `{"c":30,"d":4}`)
+	defaults.SetSourceName("defaults.json")
+	o.Merge(defaults, nil)
+	overlay := mustUnmarshal(t, `{"b":{"y":2},"a":10}`)
+	overlay.SetSourceName("overlay.json")
+	o.DeepMerge(overlay)
+	expected := map[string]Provenance{
+		"a": {"overlay.json", 1},
+		"b": {"overlay.json", 0},
+		"c": {"defaults.json", 0},
+		"d": {"defaults.json", 1},
+	}
+	for k, e := range expected {
+		if p, ok := o.Provenance(k); !ok || p != e {
+			t.Error("Provenance of", k, p, ok)
+		}
+	}
+	b, _ := o.GetOrderedMap("b")
+	if p, ok := b.Provenance("y"); !ok || p != (Provenance{"overlay.json", 0}) {
+		t.Error("Provenance of nested key", p, ok)
+	}
+	if _, ok := b.Provenance("x"); ok {
+		t.Error("Provenance of a key not merged")
+	}
+	// merging an unnamed map keeps the provenance of other keys
+	o.Merge(mustUnmarshal(t, `{"a":0}`), nil)
+	if _, ok := o.Provenance("a"); ok {
+		t.Error("Provenance kept after merging an unnamed map")
+	}
+	if p, ok := o.Provenance("c"); !ok || p != (Provenance{"defaults.json", 0}) {
+		t.Error("Provenance lost after merging an unnamed map", p, ok)
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	// the example from RFC 7396
+	o := mustUnmarshal(t, `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`)
+	patch := mustUnmarshal(t, `{"title":"Hello!","phoneNumber":"+01-555-1234","author":{"familyName":null},"tags":["example"]}`)
+	if err := o.ApplyMergePatch(patch); err != nil {
+		t.Fatal("ApplyMergePatch", err)
+	}
+	b, _ := json.Marshal(o)
+	expected := `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-555-1234"}`
+	if string(b) != expected {
+		t.Error("ApplyMergePatch", string(b))
+	}
+	tests := []struct{ target, patch, expected string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{"a":"foo"}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, test := range tests {
+		o := mustUnmarshal(t, test.target)
+		o.ApplyMergePatch(mustUnmarshal(t, test.patch))
+		b, _ := json.Marshal(o)
+		if string(b) != test.expected {
+			t.Error("ApplyMergePatch", test.target, test.patch, string(b))
+		}
+	}
+}
+
+func TestApplyMergePatchNullRecord(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":2,"n":{"x":1,"y":2}}`)
+	patch := New()
+	patch.SetNullMode(NullRecord)
+	if err := json.Unmarshal([]byte(`{"a":null,"c":3,"n":{"x":null,"z":3}}`), patch); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if err := o.ApplyMergePatch(*patch); err != nil {
+		t.Fatal("ApplyMergePatch", err)
+	}
+	b, _ := json.Marshal(o)
+	if string(b) != `{"b":2,"n":{"y":2,"z":3},"c":3}` {
+		t.Error("ApplyMergePatch with recorded nulls", string(b))
+	}
+	// recorded nulls of a new nested map are not added
+	o = mustUnmarshal(t, `{"a":1}`)
+	patch = New()
+	patch.SetNullMode(NullRecord)
+	if err := json.Unmarshal([]byte(`{"m":{"x":null,"y":1}}`), patch); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	o.ApplyMergePatch(*patch)
+	b, _ = json.Marshal(o)
+	if string(b) != `{"a":1,"m":{"y":1}}` {
+		t.Error("ApplyMergePatch new map with recorded nulls", string(b))
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	base := mustUnmarshal(t, `{"a":1,"b":2,"c":{"x":1,"y":2},"d":4,"e":5}`)
+	ours := mustUnmarshal(t, `{"a":10,"n1":1,"b":2,"c":{"x":10,"y":2},"d":40,"e":5}`)
+	theirs := mustUnmarshal(t, `{"a":1,"b":20,"c":{"x":1,"y":20},"d":41,"n2":2}`)
+	merged, conflicts, err := Merge3(base, ours, theirs)
+	if !errors.Is(err, ErrConflict) || err.Error() != "orderedmap: merge conflict: d" {
+		t.Error("Merge3 error", err)
+	}
+	b, _ := json.Marshal(merged)
+	expected := `{"a":10,"n1":1,"b":20,"c":{"x":10,"y":20},"d":40,"n2":2}`
+	if string(b) != expected {
+		t.Error("Merge3", string(b), "!=", expected)
+	}
+	expectedConflicts := []Conflict{{[]string{"d"}, float64(4), float64(40), float64(41)}}
+	if !reflect.DeepEqual(conflicts, expectedConflicts) {
+		t.Error("Merge3 conflicts", conflicts)
+	}
+	// the inputs are not modified
+	b, _ = json.Marshal(ours)
+	if string(b) != `{"a":10,"n1":1,"b":2,"c":{"x":10,"y":2},"d":40,"e":5}` {
+		t.Error("Merge3 modified ours", string(b))
+	}
+}
+
+func TestMerge3Order(t *testing.T) {
+	base := mustUnmarshal(t, `{"a":1,"b":2,"c":3}`)
+	ours := mustUnmarshal(t, `{"a":1,"b":2,"c":3,"d":4}`)
+	theirs := mustUnmarshal(t, `{"c":3,"b":2,"a":1}`)
+	merged, conflicts, err := Merge3(base, ours, theirs)
+	if len(conflicts) != 0 || err != nil {
+		t.Error("Unexpected conflicts", conflicts, err)
+	}
+	b, _ := json.Marshal(merged)
+	if string(b) != `{"c":3,"d":4,"b":2,"a":1}` {
+		t.Error("Merge3 reordered by theirs", string(b))
+	}
+	// deleted on one side and changed on the other is a conflict
+	theirs = mustUnmarshal(t, `{"a":1,"b":2}`)
+	ours = mustUnmarshal(t, `{"a":1,"b":2,"c":30}`)
+	merged, conflicts, err = Merge3(base, ours, theirs)
+	if len(conflicts) != 1 || conflicts[0].Theirs != nil || !errors.Is(err, ErrConflict) {
+		t.Error("Delete and change conflict", conflicts)
+	}
+	b, _ = json.Marshal(merged)
+	if string(b) != `{"a":1,"b":2,"c":30}` {
+		t.Error("Merge3 conflict keeps ours", string(b))
+	}
+}
+
+func TestMerge3Provenance(t *testing.T) {
+	base := mustUnmarshal(t, `{"a":1,"b":2,"c":{"x":1,"y":2}}`)
+	ours := mustUnmarshal(t, `{"a":10,"b":2,"c":{"x":10,"y":2}}`)
+	theirs := mustUnmarshal(t, `{"b":20,"a":1,"c":{"x":1,"y":20}}`)
+	merged, _, _ := Merge3(base, ours, theirs)
+	if _, ok := merged.Provenance("a"); ok {
+		t.Error("Provenance recorded without named sources")
+	}
+	ours.SetSourceName("local.json")
+	theirs.SetSourceName("remote.json")
+	merged, _, _ = Merge3(base, ours, theirs)
+	expected := map[string]Provenance{
+		"a": {"local.json", 0},
+		"b": {"remote.json", 0},
+	}
+	for k, e := range expected {
+		if p, ok := merged.Provenance(k); !ok || p != e {
+			t.Error("Provenance of", k, p, ok)
+		}
+	}
+	c, _ := merged.GetOrderedMap("c")
+	if p, ok := c.Provenance("y"); !ok || p != (Provenance{"remote.json", 1}) {
+		t.Error("Provenance of nested key", p, ok)
+	}
+	merged.Set("a", 3)
+	if _, ok := merged.Provenance("a"); ok {
+		t.Error("Provenance kept after Set")
+	}
+}
@@ -0,0 +1,74 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+)
+
+// WriteMultipart writes the map to w as a multipart/form-data body with one
+// form field per entry, in map order. String and []byte values are written
+// as is and other values as JSON. It returns the content type of the body,
+// including the boundary.
+func (o *OrderedMap) WriteMultipart(w io.Writer) (string, error) {
+	mw := multipart.NewWriter(w)
+	for _, k := range o.Keys() {
+		var data []byte
+		switch v := o.values[k].(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			data = b
+		}
+		part, err := mw.CreateFormField(k)
+		if err != nil {
+			return "", err
+		}
+		if _, err := part.Write(data); err != nil {
+			return "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	return mw.FormDataContentType(), nil
+}
+
+// ParseMultipart reads a multipart/form-data body with the given content
+// type into a new map with one entry per part, in the order of the parts.
+// Values are stored as strings. If a form field name is repeated, the last
+// value is kept in the position of the first.
+func ParseMultipart(r io.Reader, contentType string) (*OrderedMap, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, fmt.Errorf("orderedmap: content type %q is not multipart/form-data", contentType)
+	}
+	o := New()
+	mr := multipart.NewReader(r, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return o, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		o.Set(part.FormName(), string(data))
+	}
+}
@@ -0,0 +1,47 @@
+package orderedmap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteMultipart(t *testing.T) {
+	o := New()
+	o.Set("z", "last letter")
+	o.Set("a", []byte("raw bytes"))
+	o.Set("m", 3)
+	nested := New()
+	nested.Set("y", 1)
+	nested.Set("x", 2)
+	o.Set("json", nested)
+	var buf bytes.Buffer
+	contentType, err := o.WriteMultipart(&buf)
+	if err != nil {
+		t.Fatal("WriteMultipart", err)
+	}
+	if !strings.HasPrefix(contentType, "multipart/form-data; boundary=") {
+		t.Error("Content type", contentType)
+	}
+	parsed, err := ParseMultipart(&buf, contentType)
+	if err != nil {
+		t.Fatal("ParseMultipart", err)
+	}
+	expectedKeys := []string{"z", "a", "m", "json"}
+	expectedValues := []string{"last letter", "raw bytes", "3", `{"y":1,"x":2}`}
+	k := parsed.Keys()
+	if len(k) != len(expectedKeys) {
+		t.Fatal("Parsed key count", len(k))
+	}
+	for i := range k {
+		if k[i] != expectedKeys[i] {
+			t.Error("Multipart key order", i, k[i], "!=", expectedKeys[i])
+		}
+		if v, _ := parsed.Get(k[i]); v != expectedValues[i] {
+			t.Error("Multipart value", k[i], v, "!=", expectedValues[i])
+		}
+	}
+	if _, err := ParseMultipart(&buf, "application/json"); err == nil {
+		t.Error("Expected error for non multipart content type")
+	}
+}
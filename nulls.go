@@ -0,0 +1,112 @@
+package orderedmap
+
+// NullMode selects how UnmarshalJSON decodes object members whose value is
+// null.
+type NullMode int
+
+const (
+	// NullKeep keeps null members as keys with a nil value. This is the
+	// default.
+	NullKeep NullMode = iota
+	// NullDrop leaves null members out of the map.
+	NullDrop
+	// NullRecord leaves null members out of the map and records their keys,
+	// which ExplicitNulls returns. This distinguishes a member set to null
+	// from an absent one, as JSON Merge Patch requires.
+	NullRecord
+)
+
+// SetNullMode sets how UnmarshalJSON decodes null members. The setting is
+// inherited by nested maps.
+func (o *OrderedMap) SetNullMode(mode NullMode) {
+	o.nullMode = mode
+}
+
+// ExplicitNulls returns the keys of the null members left out of the map by
+// the last UnmarshalJSON with NullRecord, in the order they appeared. Setting
+// or deleting a key removes it from the list.
+func (o *OrderedMap) ExplicitNulls() []string {
+	if o == nil {
+		return []string{}
+	}
+	return append([]string{}, o.nulls...)
+}
+
+// Lookup returns the value of key and distinguishes the three states a key
+// can be in: absent (present is false), explicitly null (isNull is true),
+// or set to a non-null value. A null member recorded with NullRecord counts
+// as present and null.
+func (o *OrderedMap) Lookup(key string) (value interface{}, isNull bool, present bool) {
+	if o == nil {
+		return nil, false, false
+	}
+	key = o.canonicalKey(key)
+	if v, ok := o.values[key]; ok {
+		return v, v == nil, true
+	}
+	if containsKey(o.nulls, key) {
+		return nil, true, true
+	}
+	return nil, false, false
+}
+
+// IsNull reports whether key was explicitly set to null, either by a null
+// value in the map or by a null member recorded with NullRecord. It returns
+// false for keys that are absent, so callers can tell "set to null" from
+// "not present".
+func (o *OrderedMap) IsNull(key string) bool {
+	_, isNull, _ := o.Lookup(key)
+	return isNull
+}
+
+// SetNull sets the value of key to null, keeping the key in the map so that
+// it is marshalled as null.
+func (o *OrderedMap) SetNull(key string) {
+	o.Set(key, nil)
+}
+
+// applyNullMode removes the null members of o and its nested maps according
+// to their null mode.
+func (o *OrderedMap) applyNullMode() {
+	keys := o.keys[:0]
+	for _, k := range o.keys {
+		switch v := o.values[k].(type) {
+		case nil:
+			delete(o.values, k)
+			if o.nullMode == NullRecord {
+				o.nulls = append(o.nulls, k)
+			}
+			continue
+		case OrderedMap:
+			v.applyNullMode()
+			o.values[k] = v
+		case []interface{}:
+			applyNullModeSlice(v)
+		}
+		keys = append(keys, k)
+	}
+	o.keys = keys
+}
+
+func applyNullModeSlice(s []interface{}) {
+	for i, v := range s {
+		switch v := v.(type) {
+		case OrderedMap:
+			v.applyNullMode()
+			s[i] = v
+		case []interface{}:
+			applyNullModeSlice(v)
+		}
+	}
+}
+
+// removeKey returns keys without key, reusing its backing array.
+func removeKey(keys []string, key string) []string {
+	out := keys[:0]
+	for _, k := range keys {
+		if k != key {
+			out = append(out, k)
+		}
+	}
+	return out
+}
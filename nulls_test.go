@@ -0,0 +1,98 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSetNullMode(t *testing.T) {
+	input := `{"a":null,"b":1,"c":{"d":null,"e":[{"f":null}]},"g":null}`
+	tests := []struct {
+		mode     NullMode
+		expected string
+		nulls    []string
+	}{
+		{NullKeep, input, nil},
+		{NullDrop, `{"b":1,"c":{"e":[{}]}}`, nil},
+		{NullRecord, `{"b":1,"c":{"e":[{}]}}`, []string{"a", "g"}},
+	}
+	for _, test := range tests {
+		o := New()
+		o.SetNullMode(test.mode)
+		if err := json.Unmarshal([]byte(input), o); err != nil {
+			t.Fatal("Unmarshal", err)
+		}
+		b, _ := json.Marshal(o)
+		if string(b) != test.expected {
+			t.Error("SetNullMode", test.mode, string(b), "!=", test.expected)
+		}
+		if !reflect.DeepEqual(o.ExplicitNulls(), append([]string{}, test.nulls...)) {
+			t.Error("ExplicitNulls", test.mode, o.ExplicitNulls())
+		}
+	}
+	o := New()
+	o.SetNullMode(NullRecord)
+	if err := json.Unmarshal([]byte(input), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	c, _ := o.GetOrderedMap("c")
+	if !reflect.DeepEqual(c.ExplicitNulls(), []string{"d"}) {
+		t.Error("nested ExplicitNulls", c.ExplicitNulls())
+	}
+	o.Set("a", 1)
+	if !reflect.DeepEqual(o.ExplicitNulls(), []string{"g"}) {
+		t.Error("ExplicitNulls after Set", o.ExplicitNulls())
+	}
+}
+
+func TestIsNull(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":null,"b":1}`)
+	if !o.IsNull("a") || o.IsNull("b") || o.IsNull("c") {
+		t.Error("IsNull", o.IsNull("a"), o.IsNull("b"), o.IsNull("c"))
+	}
+	o.SetNull("c")
+	if !o.IsNull("c") {
+		t.Error("IsNull after SetNull")
+	}
+	b, _ := json.Marshal(o)
+	if string(b) != `{"a":null,"b":1,"c":null}` {
+		t.Error("SetNull", string(b))
+	}
+	r := New()
+	r.SetNullMode(NullRecord)
+	if err := json.Unmarshal([]byte(`{"a":null,"b":1}`), r); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if !r.IsNull("a") || r.IsNull("b") || r.IsNull("c") {
+		t.Error("IsNull with NullRecord")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":null,"b":1}`)
+	tests := []struct {
+		key     string
+		value   interface{}
+		isNull  bool
+		present bool
+	}{
+		{"a", nil, true, true},
+		{"b", float64(1), false, true},
+		{"c", nil, false, false},
+	}
+	for _, test := range tests {
+		v, isNull, present := o.Lookup(test.key)
+		if v != test.value || isNull != test.isNull || present != test.present {
+			t.Error("Lookup", test.key, v, isNull, present)
+		}
+	}
+	r := New()
+	r.SetNullMode(NullRecord)
+	if err := json.Unmarshal([]byte(`{"a":null}`), r); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if v, isNull, present := r.Lookup("a"); v != nil || !isNull || !present {
+		t.Error("Lookup recorded null", v, isNull, present)
+	}
+}
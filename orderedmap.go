@@ -3,7 +3,19 @@ package orderedmap
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"sort"
+	"strings"
+)
+
+var (
+	// ErrKeyNotFound is returned when an operation requires a key that is
+	// not in the map.
+	ErrKeyNotFound = errors.New("orderedmap: key not found")
+	// ErrKeyExists is returned when an operation would overwrite a key that
+	// is already in the map.
+	ErrKeyExists = errors.New("orderedmap: key already exists")
 )
 
 type Pair struct {
@@ -19,6 +31,18 @@ func (kv *Pair) Value() interface{} {
 	return kv.value
 }
 
+// NewPair returns a pair of key and value, eg for use with FromPairs or
+// SetMany.
+func NewPair(key string, value interface{}) *Pair {
+	return &Pair{key, value}
+}
+
+// SetValue sets the value of the pair. It does not change any map the pair
+// was read from.
+func (kv *Pair) SetValue(value interface{}) {
+	kv.value = value
+}
+
 type ByPair struct {
 	Pairs    []*Pair
 	LessFunc func(a *Pair, j *Pair) bool
@@ -28,10 +52,39 @@ func (a ByPair) Len() int           { return len(a.Pairs) }
 func (a ByPair) Swap(i, j int)      { a.Pairs[i], a.Pairs[j] = a.Pairs[j], a.Pairs[i] }
 func (a ByPair) Less(i, j int) bool { return a.LessFunc(a.Pairs[i], a.Pairs[j]) }
 
+// OrderedMap is a map of JSON keys and values that keeps its keys in order.
+// The zero value is an empty map ready to use, which unlike a map returned
+// by New does not escape HTML. A nil *OrderedMap reads as an empty map, and
+// methods returning a new map from it return an empty one, but setting a
+// key of one panics.
 type OrderedMap struct {
-	keys       []string
-	values     map[string]interface{}
-	escapeHTML bool
+	keys                []string
+	values              map[string]interface{}
+	escapeHTML          bool
+	format              Format
+	escapeProfile       EscapeProfile
+	guard               *mutationGuard
+	missing             func(key string) (interface{}, bool)
+	typedArrays         bool
+	maxDepth            int
+	noPool              bool
+	sourceName          string
+	provenance          map[string]Provenance
+	timeLayout          string
+	stringers           bool
+	retainRaw           bool
+	raw                 map[string]json.RawMessage
+	nullMode            NullMode
+	nulls               []string
+	keyTransform        func(key string) string
+	bigNumbers          bool
+	timeDecoder         TimeDecoder
+	createPaths         bool
+	constraints         []pathConstraint
+	numberFormatter     NumberFormatter
+	escapeHTMLRecursive bool
+	unsupportedMode     UnsupportedMode
+	dirty               *dirtyTracker
 }
 
 func New() *OrderedMap {
@@ -42,16 +95,74 @@ func New() *OrderedMap {
 	return &o
 }
 
+// FromPairs returns a new map containing pairs in order. Later pairs
+// overwrite the values of earlier pairs with the same key.
+func FromPairs(pairs []Pair) OrderedMap {
+	o := New()
+	for _, p := range pairs {
+		o.Set(p.key, p.value)
+	}
+	return *o
+}
+
 func (o *OrderedMap) SetEscapeHTML(on bool) {
 	o.escapeHTML = on
+	o.escapeHTMLRecursive = false
+}
+
+// SetEscapeHTMLRecursive sets whether HTML characters are escaped in the
+// whole document when the map is marshalled, overriding the settings of
+// nested maps, including those added after the call. By default a nested
+// map escapes HTML if it or any map containing it does. Calling
+// SetEscapeHTML removes the override. FormatCanonical never escapes HTML.
+func (o *OrderedMap) SetEscapeHTMLRecursive(on bool) {
+	o.escapeHTML = on
+	o.escapeHTMLRecursive = true
 }
 
 func (o *OrderedMap) Get(key string) (interface{}, bool) {
+	if o == nil {
+		return nil, false
+	}
+	key = o.canonicalKey(key)
 	val, exists := o.values[key]
+	if !exists && o.missing != nil {
+		return o.missing(key)
+	}
 	return val, exists
 }
 
+// Computed is a value that is evaluated when the map is marshalled, in its
+// position in the map. See SetComputed.
+type Computed func() (interface{}, error)
+
+// MarshalJSON evaluates c and returns the JSON encoding of the result.
+func (c Computed) MarshalJSON() ([]byte, error) {
+	v, err := c()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// SetComputed sets key to a value that is computed by fn each time the map
+// is marshalled, eg a timestamp or a summary of the other values. Get
+// returns the Computed func rather than its result.
+func (o *OrderedMap) SetComputed(key string, fn func() (interface{}, error)) {
+	o.Set(key, Computed(fn))
+}
+
+// SetMissingHandler sets a func that Get consults when a key is not in the
+// map, eg to compute a value on demand or fall back to another map. The
+// value it returns is not added to the map. Pass nil to remove the handler.
+func (o *OrderedMap) SetMissingHandler(handler func(key string) (interface{}, bool)) {
+	o.missing = handler
+}
+
 func (o *OrderedMap) Set(key string, value interface{}) {
+	o.init()
+	key = o.canonicalKey(key)
+	o.touch(key)
 	_, exists := o.values[key]
 	if !exists {
 		o.keys = append(o.keys, key)
@@ -59,12 +170,48 @@ func (o *OrderedMap) Set(key string, value interface{}) {
 	o.values[key] = value
 }
 
+// Append adds key to the end of the map without checking whether it is
+// already in use, which makes it faster than Set when building a map from
+// keys that are known to be unique. Appending a key that is already in the
+// map leaves the map with a duplicate key, so use Set when in doubt.
+func (o *OrderedMap) Append(key string, value interface{}) {
+	o.init()
+	key = o.canonicalKey(key)
+	o.touch(key)
+	o.keys = append(o.keys, key)
+	o.values[key] = value
+}
+
+// SetMany sets each of pairs in order, as Set does, growing the map once
+// for all of them.
+func (o *OrderedMap) SetMany(pairs []Pair) {
+	o.init()
+	if free := cap(o.keys) - len(o.keys); free < len(pairs) {
+		keys := make([]string, len(o.keys), len(o.keys)+len(pairs))
+		copy(keys, o.keys)
+		o.keys = keys
+	}
+	for _, p := range pairs {
+		key := o.canonicalKey(p.key)
+		o.touch(key)
+		if _, exists := o.values[key]; !exists {
+			o.keys = append(o.keys, key)
+		}
+		o.values[key] = p.value
+	}
+}
+
 func (o *OrderedMap) Delete(key string) {
+	if o == nil {
+		return
+	}
+	key = o.canonicalKey(key)
 	// check key is in use
 	_, ok := o.values[key]
 	if !ok {
 		return
 	}
+	o.touch(key)
 	// remove from keys
 	for i, k := range o.keys {
 		if k == key {
@@ -76,19 +223,468 @@ func (o *OrderedMap) Delete(key string) {
 	delete(o.values, key)
 }
 
+// DeleteMany removes each of keys from the map in a single pass over the
+// map's keys. Keys that are not in the map are ignored.
+func (o *OrderedMap) DeleteMany(keys ...string) {
+	if o == nil {
+		return
+	}
+	remove := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		key = o.canonicalKey(key)
+		if _, ok := o.values[key]; ok {
+			o.touch(key)
+			remove[key] = true
+			delete(o.values, key)
+		}
+	}
+	if len(remove) == 0 {
+		return
+	}
+	kept := o.keys[:0]
+	for _, k := range o.keys {
+		if !remove[k] {
+			kept = append(kept, k)
+		}
+	}
+	o.keys = kept
+}
+
+// RenameKey changes the name of oldKey to newKey, keeping its value and its
+// position in the map.
+func (o *OrderedMap) RenameKey(oldKey, newKey string) error {
+	if o == nil {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, oldKey)
+	}
+	oldKey, newKey = o.canonicalKey(oldKey), o.canonicalKey(newKey)
+	value, ok := o.values[oldKey]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, oldKey)
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	if _, exists := o.values[newKey]; exists {
+		return fmt.Errorf("%w: %q", ErrKeyExists, newKey)
+	}
+	o.touch(oldKey)
+	o.touch(newKey)
+	for i, k := range o.keys {
+		if k == oldKey {
+			o.keys[i] = newKey
+			break
+		}
+	}
+	delete(o.values, oldKey)
+	o.values[newKey] = value
+	return nil
+}
+
+// Oldest returns the first entry in the map, or nil if the map is empty.
+func (o *OrderedMap) Oldest() *Pair {
+	if o == nil || len(o.keys) == 0 {
+		return nil
+	}
+	key := o.keys[0]
+	return &Pair{key, o.values[key]}
+}
+
+// Newest returns the last entry in the map, or nil if the map is empty.
+func (o *OrderedMap) Newest() *Pair {
+	if o == nil || len(o.keys) == 0 {
+		return nil
+	}
+	key := o.keys[len(o.keys)-1]
+	return &Pair{key, o.values[key]}
+}
+
+// PopFront removes and returns the first entry in the map, or returns nil if
+// the map is empty.
+func (o *OrderedMap) PopFront() *Pair {
+	p := o.Oldest()
+	if p == nil {
+		return nil
+	}
+	o.touch(p.key)
+	o.keys = o.keys[1:]
+	delete(o.values, p.key)
+	return p
+}
+
+// PopBack removes and returns the last entry in the map, or returns nil if
+// the map is empty.
+func (o *OrderedMap) PopBack() *Pair {
+	p := o.Newest()
+	if p == nil {
+		return nil
+	}
+	o.touch(p.key)
+	o.keys = o.keys[:len(o.keys)-1]
+	delete(o.values, p.key)
+	return p
+}
+
+// init prepares a zero OrderedMap for adding keys, so that a zero value, as
+// declared in a struct, can be used like one returned by New. Setting a key
+// of a nil *OrderedMap panics, as it does for a nil Go map.
+func (o *OrderedMap) init() {
+	if o == nil {
+		panic("orderedmap: assignment to entry in nil *OrderedMap")
+	}
+	if o.values == nil {
+		o.values = map[string]interface{}{}
+	}
+}
+
+// touch is called before key is added, changed or removed.
+func (o *OrderedMap) touch(key string) {
+	if o.guard != nil {
+		_, existed := o.values[key]
+		o.guard.record(key, existed)
+	}
+	if o.dirty != nil {
+		o.dirty.record(key, o.values)
+	}
+	if o.raw != nil {
+		delete(o.raw, key)
+	}
+	if o.provenance != nil {
+		delete(o.provenance, key)
+	}
+	if o.nulls != nil {
+		o.nulls = removeKey(o.nulls, key)
+	}
+}
+
+// clone returns a shallow copy of o with the same settings. Nested values
+// are shared with o. For a nil o it returns an empty map as returned by New.
+func (o *OrderedMap) clone() *OrderedMap {
+	if o == nil {
+		return New()
+	}
+	c := *o
+	c.keys = append(make([]string, 0, len(o.keys)), o.keys...)
+	c.values = make(map[string]interface{}, len(o.values))
+	for k, v := range o.values {
+		c.values[k] = v
+	}
+	if o.raw != nil {
+		c.raw = make(map[string]json.RawMessage, len(o.raw))
+		for k, v := range o.raw {
+			c.raw[k] = v
+		}
+	}
+	if o.provenance != nil {
+		c.provenance = make(map[string]Provenance, len(o.provenance))
+		for k, v := range o.provenance {
+			c.provenance[k] = v
+		}
+	}
+	if o.nulls != nil {
+		c.nulls = append([]string{}, o.nulls...)
+	}
+	c.constraints = o.constraints[:len(o.constraints):len(o.constraints)]
+	if o.dirty != nil {
+		c.dirty = o.dirty.clone()
+	}
+	c.guard = nil
+	return &c
+}
+
+// subMap returns a new map with the settings of o, containing the entries
+// of o whose keys satisfy keep, in order. For a nil o it returns an empty
+// map as returned by New.
+func (o *OrderedMap) subMap(keep func(key string) bool) OrderedMap {
+	if o == nil {
+		return *New()
+	}
+	s := *o
+	s.keys = []string{}
+	s.values = map[string]interface{}{}
+	s.guard = nil
+	s.raw = nil
+	s.sourceName = ""
+	s.provenance = nil
+	s.nulls = nil
+	s.constraints = nil
+	s.dirty = nil
+	for _, k := range o.keys {
+		if keep(k) {
+			s.keys = append(s.keys, k)
+			s.values[k] = o.values[k]
+		}
+	}
+	return s
+}
+
+// Pick returns a new map containing only the given keys, in the order they
+// appear in o. Keys that are not in o are ignored.
+func (o *OrderedMap) Pick(keys ...string) OrderedMap {
+	pick := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		pick[o.canonicalKey(k)] = true
+	}
+	return o.subMap(func(key string) bool {
+		return pick[key]
+	})
+}
+
+// Omit returns a new map containing every key of o except the given keys,
+// in order.
+func (o *OrderedMap) Omit(keys ...string) OrderedMap {
+	omit := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		omit[o.canonicalKey(k)] = true
+	}
+	return o.subMap(func(key string) bool {
+		return !omit[key]
+	})
+}
+
+// KeysWithPrefix returns the keys that start with prefix, in order. prefix
+// is transformed by the key transform, if any.
+func (o *OrderedMap) KeysWithPrefix(prefix string) []string {
+	keys := []string{}
+	if o == nil {
+		return keys
+	}
+	prefix = o.canonicalKey(prefix)
+	for _, k := range o.keys {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// PickPrefix returns a new map containing only the keys that start with
+// prefix, in the order they appear in o. prefix is transformed as by
+// KeysWithPrefix.
+func (o *OrderedMap) PickPrefix(prefix string) OrderedMap {
+	prefix = o.canonicalKey(prefix)
+	return o.subMap(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// Filter returns a new map containing the entries for which pred returns
+// true, in the order they appear in o, with the settings of o.
+func (o *OrderedMap) Filter(pred func(key string, v interface{}) bool) OrderedMap {
+	return o.subMap(func(key string) bool {
+		return pred(key, o.values[key])
+	})
+}
+
+// Partition returns two new maps with the settings of o, the first
+// containing the entries for which pred returns true and the second the
+// rest, both in the order they appear in o. pred is called once per entry.
+func (o *OrderedMap) Partition(pred func(*Pair) bool) (matched, rest OrderedMap) {
+	if o == nil {
+		return *New(), *New()
+	}
+	matches := make(map[string]bool, len(o.keys))
+	for _, key := range o.keys {
+		p := Pair{key, o.values[key]}
+		matches[key] = pred(&p)
+	}
+	matched = o.subMap(func(key string) bool { return matches[key] })
+	rest = o.subMap(func(key string) bool { return !matches[key] })
+	return matched, rest
+}
+
+// GroupBy returns a new map from the names returned by keyFn to maps of the
+// entries with that name, in the order they appear in o. Groups are ordered
+// by their first entry. The result and the groups have the settings of o,
+// and names are transformed by the key transform, if any.
+func (o *OrderedMap) GroupBy(keyFn func(*Pair) string) OrderedMap {
+	if o == nil {
+		return *New()
+	}
+	none := func(string) bool { return false }
+	groups := o.subMap(none)
+	for _, key := range o.keys {
+		value := o.values[key]
+		p := Pair{key, value}
+		name := groups.canonicalKey(keyFn(&p))
+		group, ok := groups.values[name].(OrderedMap)
+		if !ok {
+			group = o.subMap(none)
+			groups.keys = append(groups.keys, name)
+		}
+		group.keys = append(group.keys, key)
+		group.values[key] = value
+		groups.values[name] = group
+	}
+	return groups
+}
+
+// Slice returns a new map containing the entries of o at positions from up
+// to but not including to, with the settings of o. Positions outside the map
+// are clamped to it, so paging past the end returns an empty map.
+func (o *OrderedMap) Slice(from, to int) OrderedMap {
+	if o == nil {
+		return *New()
+	}
+	if from < 0 {
+		from = 0
+	}
+	if to > len(o.keys) {
+		to = len(o.keys)
+	}
+	if to < 0 {
+		to = 0
+	}
+	if from > to {
+		from = to
+	}
+	s := o.subMap(func(string) bool { return false })
+	s.keys = make([]string, to-from)
+	copy(s.keys, o.keys[from:to])
+	for _, k := range s.keys {
+		s.values[k] = o.values[k]
+	}
+	return s
+}
+
 func (o *OrderedMap) Keys() []string {
+	if o == nil {
+		return nil
+	}
 	return o.keys
 }
 
+// KeysCopy returns a copy of the keys in order. Unlike the slice returned
+// by Keys, it can be modified without affecting the map.
+func (o *OrderedMap) KeysCopy() []string {
+	if o == nil {
+		return []string{}
+	}
+	return append(make([]string, 0, len(o.keys)), o.keys...)
+}
+
 func (o *OrderedMap) Values() map[string]interface{} {
+	if o == nil {
+		return nil
+	}
 	return o.values
 }
 
+// ValuesOrdered returns the values of the map in key order.
+func (o *OrderedMap) ValuesOrdered() []interface{} {
+	if o == nil {
+		return []interface{}{}
+	}
+	values := make([]interface{}, len(o.keys))
+	for i, key := range o.keys {
+		values[i] = o.values[key]
+	}
+	return values
+}
+
+// Entries returns a copy of the key/value pairs in map order. Later changes
+// to the map do not affect the returned slice.
+func (o *OrderedMap) Entries() []Pair {
+	if o == nil {
+		return []Pair{}
+	}
+	pairs := make([]Pair, len(o.keys))
+	for i, key := range o.keys {
+		pairs[i] = Pair{key, o.values[key]}
+	}
+	return pairs
+}
+
+// ForEachPair calls fn with each key/value pair in map order until fn
+// returns false. Pairs are passed by value, so iterating does not allocate.
+// fn must not add or delete keys.
+func (o *OrderedMap) ForEachPair(fn func(Pair) bool) {
+	if o == nil {
+		return
+	}
+	if o.guard != nil {
+		defer o.guard.endIteration(o, o.guard.begin(), "ForEachPair")
+	}
+	for _, key := range o.keys {
+		if !fn(Pair{key, o.values[key]}) {
+			return
+		}
+	}
+}
+
+// ForEach calls fn with each key and value in map order until fn returns
+// false. fn must not add or delete keys.
+func (o *OrderedMap) ForEach(fn func(key string, value interface{}) bool) {
+	if o == nil {
+		return
+	}
+	if o.guard != nil {
+		defer o.guard.endIteration(o, o.guard.begin(), "ForEach")
+	}
+	for _, key := range o.keys {
+		if !fn(key, o.values[key]) {
+			return
+		}
+	}
+}
+
+// Reduce calls fn with an accumulator and each pair in map order, passing
+// the result of each call to the next, and returns the final accumulator.
+// initial is returned for an empty map.
+func (o *OrderedMap) Reduce(initial interface{}, fn func(acc interface{}, p *Pair) interface{}) interface{} {
+	acc := initial
+	if o == nil {
+		return acc
+	}
+	for _, key := range o.keys {
+		p := Pair{key, o.values[key]}
+		acc = fn(acc, &p)
+	}
+	return acc
+}
+
+// Any reports whether pred returns true for any pair, calling it in map
+// order until it does.
+func (o *OrderedMap) Any(pred func(p *Pair) bool) bool {
+	_, found := o.Find(pred)
+	return found
+}
+
+// All reports whether pred returns true for every pair, calling it in map
+// order until it returns false. It returns true for an empty map.
+func (o *OrderedMap) All(pred func(p *Pair) bool) bool {
+	_, found := o.Find(func(p *Pair) bool {
+		return !pred(p)
+	})
+	return !found
+}
+
+// Find returns the first pair in map order for which pred returns true.
+func (o *OrderedMap) Find(pred func(p *Pair) bool) (*Pair, bool) {
+	if o == nil {
+		return nil, false
+	}
+	for _, key := range o.keys {
+		p := Pair{key, o.values[key]}
+		if pred(&p) {
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
 // SortKeys Sort the map keys using your sort func
 func (o *OrderedMap) SortKeys(sortFunc func(keys []string)) {
 	sortFunc(o.keys)
 }
 
+// SortKeysRecursive sorts the keys of the map and of every nested map,
+// including maps in arrays, using sortFunc.
+func (o *OrderedMap) SortKeysRecursive(sortFunc func(keys []string)) {
+	o.SortWith(func(path []string, keys []string) {
+		sortFunc(keys)
+	})
+}
+
 // Sort Sort the map using your sort func
 func (o *OrderedMap) Sort(lessFunc func(a *Pair, b *Pair) bool) {
 	pairs := make([]*Pair, len(o.keys))
@@ -104,163 +700,239 @@ func (o *OrderedMap) Sort(lessFunc func(a *Pair, b *Pair) bool) {
 }
 
 func (o *OrderedMap) UnmarshalJSON(b []byte) error {
-	if o.values == nil {
-		o.values = map[string]interface{}{}
+	return o.unmarshalJSON(b, nil, nil)
+}
+
+// unmarshalJSON decodes b into o, recording the keys in keys[:0] if keys is
+// not nil so that a caller can reuse a slice, and describing the input in
+// info if it is not nil.
+func (o *OrderedMap) unmarshalJSON(b []byte, keys []string, info *DecodeInfo) error {
+	o.init()
+	var err error
+	if o.bigNumbers {
+		err = unmarshalUseNumber(b, &o.values)
+	} else {
+		err = json.Unmarshal(b, &o.values)
 	}
-	err := json.Unmarshal(b, &o.values)
 	if err != nil {
 		return err
 	}
+	o.raw = nil
+	if o.retainRaw {
+		if err = json.Unmarshal(b, &o.raw); err != nil {
+			return err
+		}
+	}
 	dec := json.NewDecoder(bytes.NewReader(b))
+	if info != nil {
+		dec.UseNumber()
+	}
 	if _, err = dec.Token(); err != nil { // skip '{'
 		return err
 	}
-	o.keys = make([]string, 0, len(o.values))
-	return decodeOrderedMap(dec, o)
+	if keys == nil {
+		keys = make([]string, 0, len(o.values))
+	}
+	o.keys = keys[:0]
+	if err = decodeOrderedMap(dec, o, b, info); err != nil {
+		return err
+	}
+	o.nulls = nil
+	// the decoded map is the new starting point for DirtyKeys
+	o.ResetDirty()
+	if o.keyTransform != nil {
+		o.applyKeyTransform()
+	}
+	if o.nullMode != NullKeep {
+		o.applyNullMode()
+	}
+	if o.bigNumbers {
+		for k, v := range o.values {
+			o.values[k] = convertNumbers(v)
+		}
+	}
+	if o.timeDecoder != nil {
+		o.decodeTimes()
+	}
+	if o.typedArrays {
+		for k, v := range o.values {
+			o.values[k] = typeArrays(v)
+		}
+	}
+	return nil
+}
+
+// SetMaxDepth limits the nesting depth of objects and arrays accepted by
+// UnmarshalJSON, which returns an error for deeper input. The outermost
+// object has depth 1. A limit of 0, the default, leaves only the limit of
+// encoding/json. The setting is inherited by nested maps.
+func (o *OrderedMap) SetMaxDepth(depth int) {
+	o.maxDepth = depth
 }
 
-func decodeOrderedMap(dec *json.Decoder, o *OrderedMap) error {
-	hasKey := make(map[string]bool, len(o.values))
-	for {
+// decodeFrame is an object or array being decoded by decodeOrderedMap.
+type decodeFrame struct {
+	// m is the object being decoded, or nil for an array
+	m      *OrderedMap
+	hasKey map[string]bool
+	// key is the key of the value being decoded in m
+	key string
+	// s is the array being decoded and index the index of its next element
+	s     []interface{}
+	index int
+	// store is set if the decoded value replaces the value in the parent
+	store bool
+}
+
+// decodeOrderedMap reads the keys of the object following '{' in dec into
+// o, converting the nested objects in o.values into OrderedMaps. If info is
+// not nil, the tokens of input, the JSON being decoded, are recorded in it.
+// It uses an explicit stack rather than recursion so that deeply nested
+// input cannot exhaust the goroutine stack.
+func decodeOrderedMap(dec *json.Decoder, o *OrderedMap, input []byte, info *DecodeInfo) error {
+	stack := []decodeFrame{{m: o, hasKey: make(map[string]bool, len(o.values))}}
+	if info != nil {
+		info.Depth = 1
+	}
+	for len(stack) > 0 {
+		f := &stack[len(stack)-1]
+		offset := dec.InputOffset()
 		token, err := dec.Token()
 		if err != nil {
 			return err
 		}
-		if delim, ok := token.(json.Delim); ok && delim == '}' {
-			return nil
-		}
-		key := token.(string)
-		if hasKey[key] {
-			// duplicate key
-			for j, k := range o.keys {
-				if k == key {
-					copy(o.keys[j:], o.keys[j+1:])
-					break
+		var value interface{}
+		if f.m != nil {
+			if delim, ok := token.(json.Delim); ok && delim == '}' {
+				stack = popDecodeFrame(stack)
+				continue
+			}
+			key := token.(string)
+			f.key = key
+			if info != nil {
+				info.recordKey(stack, f.hasKey[key], input[offset:dec.InputOffset()])
+			}
+			if f.hasKey[key] {
+				// duplicate key
+				for j, k := range f.m.keys {
+					if k == key {
+						copy(f.m.keys[j:], f.m.keys[j+1:])
+						break
+					}
 				}
+				f.m.keys[len(f.m.keys)-1] = key
+			} else {
+				f.hasKey[key] = true
+				f.m.keys = append(f.m.keys, key)
+			}
+			offset = dec.InputOffset()
+			token, err = dec.Token()
+			if err != nil {
+				return err
 			}
-			o.keys[len(o.keys)-1] = key
+			value = f.m.values[key]
 		} else {
-			hasKey[key] = true
-			o.keys = append(o.keys, key)
+			if delim, ok := token.(json.Delim); ok && delim == ']' {
+				stack = popDecodeFrame(stack)
+				continue
+			}
+			if f.index < len(f.s) {
+				value = f.s[f.index]
+			}
+			f.index++
 		}
-
-		token, err = dec.Token()
-		if err != nil {
-			return err
+		if info != nil {
+			info.recordValue(stack, token, input[offset:dec.InputOffset()])
 		}
-		if delim, ok := token.(json.Delim); ok {
-			switch delim {
-			case '{':
-				if values, ok := o.values[key].(map[string]interface{}); ok {
-					newMap := OrderedMap{
-						keys:       make([]string, 0, len(values)),
-						values:     values,
-						escapeHTML: o.escapeHTML,
-					}
-					if err = decodeOrderedMap(dec, &newMap); err != nil {
-						return err
-					}
-					o.values[key] = newMap
-				} else if oldMap, ok := o.values[key].(OrderedMap); ok {
-					newMap := OrderedMap{
-						keys:       make([]string, 0, len(oldMap.values)),
-						values:     oldMap.values,
-						escapeHTML: o.escapeHTML,
-					}
-					if err = decodeOrderedMap(dec, &newMap); err != nil {
-						return err
-					}
-					o.values[key] = newMap
-				} else if err = decodeOrderedMap(dec, &OrderedMap{}); err != nil {
-					return err
-				}
-			case '[':
-				if values, ok := o.values[key].([]interface{}); ok {
-					if err = decodeSlice(dec, values, o.escapeHTML); err != nil {
-						return err
-					}
-				} else if err = decodeSlice(dec, []interface{}{}, o.escapeHTML); err != nil {
-					return err
-				}
+		delim, ok := token.(json.Delim)
+		if !ok {
+			continue
+		}
+		if o.maxDepth > 0 && len(stack) >= o.maxDepth {
+			return fmt.Errorf("orderedmap: exceeded max depth of %d", o.maxDepth)
+		}
+		switch delim {
+		case '{':
+			// the values were decoded by json.Unmarshal, or by an earlier
+			// occurrence of a duplicate key
+			child := decodeFrame{m: &OrderedMap{}}
+			if values, ok := value.(map[string]interface{}); ok {
+				newMap := o.child(values)
+				child.m, child.store = &newMap, true
+			} else if oldMap, ok := value.(OrderedMap); ok {
+				newMap := o.child(oldMap.values)
+				child.m, child.store = &newMap, true
 			}
+			child.hasKey = make(map[string]bool, len(child.m.values))
+			stack = append(stack, child)
+		case '[':
+			child := decodeFrame{}
+			if values, ok := value.([]interface{}); ok {
+				child.s = values
+			}
+			stack = append(stack, child)
 		}
 	}
+	return nil
 }
 
-func decodeSlice(dec *json.Decoder, s []interface{}, escapeHTML bool) error {
-	for index := 0; ; index++ {
-		token, err := dec.Token()
-		if err != nil {
-			return err
-		}
-		if delim, ok := token.(json.Delim); ok {
-			switch delim {
-			case '{':
-				if index < len(s) {
-					if values, ok := s[index].(map[string]interface{}); ok {
-						newMap := OrderedMap{
-							keys:       make([]string, 0, len(values)),
-							values:     values,
-							escapeHTML: escapeHTML,
-						}
-						if err = decodeOrderedMap(dec, &newMap); err != nil {
-							return err
-						}
-						s[index] = newMap
-					} else if oldMap, ok := s[index].(OrderedMap); ok {
-						newMap := OrderedMap{
-							keys:       make([]string, 0, len(oldMap.values)),
-							values:     oldMap.values,
-							escapeHTML: escapeHTML,
-						}
-						if err = decodeOrderedMap(dec, &newMap); err != nil {
-							return err
-						}
-						s[index] = newMap
-					} else if err = decodeOrderedMap(dec, &OrderedMap{}); err != nil {
-						return err
-					}
-				} else if err = decodeOrderedMap(dec, &OrderedMap{}); err != nil {
-					return err
-				}
-			case '[':
-				if index < len(s) {
-					if values, ok := s[index].([]interface{}); ok {
-						if err = decodeSlice(dec, values, escapeHTML); err != nil {
-							return err
-						}
-					} else if err = decodeSlice(dec, []interface{}{}, escapeHTML); err != nil {
-						return err
-					}
-				} else if err = decodeSlice(dec, []interface{}{}, escapeHTML); err != nil {
-					return err
-				}
-			case ']':
-				return nil
-			}
-		}
+// popDecodeFrame removes the last frame from stack, storing its map in the
+// parent frame.
+func popDecodeFrame(stack []decodeFrame) []decodeFrame {
+	done := stack[len(stack)-1]
+	stack = stack[:len(stack)-1]
+	if !done.store || len(stack) == 0 {
+		return stack
+	}
+	parent := &stack[len(stack)-1]
+	if parent.m != nil {
+		parent.m.values[parent.key] = *done.m
+	} else {
+		parent.s[parent.index-1] = *done.m
+	}
+	return stack
+}
+
+// child returns an empty map for decoding the nested object values into,
+// with the settings of o.
+func (o *OrderedMap) child(values map[string]interface{}) OrderedMap {
+	return OrderedMap{
+		keys:         make([]string, 0, len(values)),
+		values:       values,
+		escapeHTML:   o.escapeHTML,
+		typedArrays:  o.typedArrays,
+		maxDepth:     o.maxDepth,
+		nullMode:     o.nullMode,
+		keyTransform: o.keyTransform,
+		bigNumbers:   o.bigNumbers,
+		timeDecoder:  o.timeDecoder,
 	}
 }
 
 func (o OrderedMap) MarshalJSON() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteByte('{')
-	encoder := json.NewEncoder(&buf)
-	encoder.SetEscapeHTML(o.escapeHTML)
-	for i, k := range o.keys {
-		if i > 0 {
-			buf.WriteByte(',')
-		}
-		// add key
-		if err := encoder.Encode(k); err != nil {
-			return nil, err
-		}
-		buf.WriteByte(':')
-		// add value
-		if err := encoder.Encode(o.values[k]); err != nil {
-			return nil, err
-		}
+	if o.guard == nil {
+		return o.marshalJSON()
+	}
+	start := o.guard.begin()
+	b, err := o.marshalJSON()
+	if mutations := o.guard.end(start); len(mutations) > 0 {
+		return nil, fmt.Errorf("orderedmap: map mutated during MarshalJSON, keys %q", mutatedKeys(mutations))
+	}
+	return b, err
+}
+
+func (o OrderedMap) marshalJSON() ([]byte, error) {
+	fn, err := lookupFormat(o.format)
+	if err != nil {
+		return nil, err
+	}
+	buf := newBuffer(!o.noPool)
+	defer releaseBuffer(buf, !o.noPool)
+	if err := fn(buf, &o); err != nil {
+		return nil, err
+	}
+	if o.escapeProfile != EscapeDefault {
+		return applyEscapeProfile(buf.Bytes(), o.escapeProfile)
 	}
-	buf.WriteByte('}')
-	return buf.Bytes(), nil
+	return append([]byte(nil), buf.Bytes()...), nil
 }
@@ -2,6 +2,7 @@ package orderedmap
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
@@ -216,6 +217,33 @@ func TestMarshalJSONNoEscapeHTMLRecursive(t *testing.T) {
 	}
 }
 
+func TestSetEscapeHTMLRecursive(t *testing.T) {
+	o := New()
+	if err := json.Unmarshal([]byte(`{"x":"<>","y":[{"z":["<>"]}]}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	added := New()
+	added.Set("w", "&")
+	o.Set("added", added)
+	o.SetEscapeHTMLRecursive(false)
+	b, _ := o.MarshalJSON()
+	if string(b) != `{"x":"<>","y":[{"z":["<>"]}],"added":{"w":"&"}}` {
+		t.Error("SetEscapeHTMLRecursive(false)", string(b))
+	}
+	added.SetEscapeHTML(false)
+	o.SetEscapeHTMLRecursive(true)
+	b, _ = o.MarshalJSON()
+	if string(b) != `{"x":"\u003c\u003e","y":[{"z":["\u003c\u003e"]}],"added":{"w":"\u0026"}}` {
+		t.Error("SetEscapeHTMLRecursive(true)", string(b))
+	}
+	// SetEscapeHTML removes the override
+	o.SetEscapeHTML(false)
+	b, _ = o.MarshalJSON()
+	if string(b) != `{"x":"<>","y":[{"z":["\u003c\u003e"]}],"added":{"w":"&"}}` {
+		t.Error("SetEscapeHTML after SetEscapeHTMLRecursive", string(b))
+	}
+}
+
 func TestUnmarshalJSON(t *testing.T) {
 	s := `{
   "number": 4,
@@ -595,3 +623,800 @@ func TestOrderedMap_empty_map(t *testing.T) {
 		t.Error("Got", marshalledStr)
 	}
 }
+
+func TestOrderedMap_RenameKey(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	o.Set("c", 3)
+	if err := o.RenameKey("b", "x"); err != nil {
+		t.Fatal("RenameKey", err)
+	}
+	expectedKeys := []string{"a", "x", "c"}
+	k := o.Keys()
+	for i := range k {
+		if k[i] != expectedKeys[i] {
+			t.Error("RenameKey key order", i, k[i], "!=", expectedKeys[i])
+		}
+	}
+	v, ok := o.Get("x")
+	if !ok || v.(int) != 2 {
+		t.Error("RenameKey did not keep value")
+	}
+	if _, ok := o.Get("b"); ok {
+		t.Error("RenameKey did not remove old key")
+	}
+	if err := o.RenameKey("missing", "y"); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("RenameKey missing key", err)
+	}
+	if err := o.RenameKey("a", "c"); !errors.Is(err, ErrKeyExists) {
+		t.Error("RenameKey existing key", err)
+	}
+	if err := o.RenameKey("a", "a"); err != nil {
+		t.Error("RenameKey to same name", err)
+	}
+}
+
+func TestOrderedMap_OldestNewest(t *testing.T) {
+	o := New()
+	if o.Oldest() != nil || o.Newest() != nil {
+		t.Error("Oldest/Newest of empty map should be nil")
+	}
+	o.Set("a", 1)
+	o.Set("b", 2)
+	o.Set("c", 3)
+	if p := o.Oldest(); p.Key() != "a" || p.Value().(int) != 1 {
+		t.Error("Oldest", p.Key(), p.Value())
+	}
+	if p := o.Newest(); p.Key() != "c" || p.Value().(int) != 3 {
+		t.Error("Newest", p.Key(), p.Value())
+	}
+	// updating a value does not change its position
+	o.Set("a", 4)
+	if p := o.Oldest(); p.Key() != "a" || p.Value().(int) != 4 {
+		t.Error("Oldest after update", p.Key(), p.Value())
+	}
+}
+
+func TestOrderedMap_PopFrontPopBack(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	o.Set("c", 3)
+	if p := o.PopFront(); p.Key() != "a" || p.Value().(int) != 1 {
+		t.Error("PopFront", p.Key(), p.Value())
+	}
+	if p := o.PopBack(); p.Key() != "c" || p.Value().(int) != 3 {
+		t.Error("PopBack", p.Key(), p.Value())
+	}
+	if _, ok := o.Get("a"); ok {
+		t.Error("PopFront did not remove key")
+	}
+	if _, ok := o.Get("c"); ok {
+		t.Error("PopBack did not remove key")
+	}
+	// a popped key can be added again at the end
+	o.Set("a", 4)
+	expectedKeys := []string{"b", "a"}
+	k := o.Keys()
+	if len(k) != len(expectedKeys) {
+		t.Fatal("Key count after pop", len(k))
+	}
+	for i := range k {
+		if k[i] != expectedKeys[i] {
+			t.Error("Key order after pop", i, k[i], "!=", expectedKeys[i])
+		}
+	}
+	o.PopFront()
+	o.PopFront()
+	if o.PopFront() != nil || o.PopBack() != nil {
+		t.Error("Pop from empty map should return nil")
+	}
+}
+
+func TestOrderedMap_Entries(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	entries := o.Entries()
+	o.Set("a", 3)
+	o.Set("c", 4)
+	if len(entries) != 2 {
+		t.Fatal("Entries length", len(entries))
+	}
+	if entries[0].Key() != "a" || entries[0].Value().(int) != 1 {
+		t.Error("Entries first pair", entries[0].Key(), entries[0].Value())
+	}
+	if entries[1].Key() != "b" || entries[1].Value().(int) != 2 {
+		t.Error("Entries second pair", entries[1].Key(), entries[1].Value())
+	}
+}
+
+func TestOrderedMap_SetMissingHandler(t *testing.T) {
+	defaults := New()
+	defaults.Set("a", "default a")
+	defaults.Set("b", "default b")
+	o := New()
+	o.Set("a", "a")
+	o.SetMissingHandler(defaults.Get)
+	if v, ok := o.Get("a"); !ok || v != "a" {
+		t.Error("Get existing key", v, ok)
+	}
+	if v, ok := o.Get("b"); !ok || v != "default b" {
+		t.Error("Get missing key with handler", v, ok)
+	}
+	if _, ok := o.Get("c"); ok {
+		t.Error("Get key missing from handler")
+	}
+	if len(o.Keys()) != 1 {
+		t.Error("Missing handler value was added to map")
+	}
+	o.SetMissingHandler(nil)
+	if _, ok := o.Get("b"); ok {
+		t.Error("Get missing key after removing handler")
+	}
+}
+
+func TestOrderedMap_SetComputed(t *testing.T) {
+	calls := 0
+	o := New()
+	o.Set("a", 1)
+	o.SetComputed("count", func() (interface{}, error) {
+		calls++
+		return calls, nil
+	})
+	o.Set("b", 2)
+	if calls != 0 {
+		t.Error("Computed value evaluated before marshal")
+	}
+	for i := 1; i <= 2; i++ {
+		b, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal("Marshal with computed value", err)
+		}
+		expected := fmt.Sprintf(`{"a":1,"count":%d,"b":2}`, i)
+		if string(b) != expected {
+			t.Error("Computed value output", string(b), "!=", expected)
+		}
+	}
+	// computed values nested in other values are evaluated too
+	o.Set("nested", []interface{}{Computed(func() (interface{}, error) {
+		return "x", nil
+	})})
+	b, _ := json.Marshal(o)
+	if !strings.HasSuffix(string(b), `"nested":["x"]}`) {
+		t.Error("Nested computed value output", string(b))
+	}
+	o.SetComputed("err", func() (interface{}, error) {
+		return nil, fmt.Errorf("failed")
+	})
+	if _, err := json.Marshal(o); err == nil {
+		t.Error("Expected error from computed value")
+	}
+}
+
+func TestFromPairs(t *testing.T) {
+	src := New()
+	src.Set("b", 1)
+	src.Set("a", 2)
+	o := FromPairs(src.Entries())
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"b":1,"a":2}` {
+		t.Error("FromPairs output", string(b))
+	}
+	o = FromPairs([]Pair{{"x", 1}, {"y", 2}, {"x", 3}})
+	b, _ = json.Marshal(o)
+	if string(b) != `{"x":3,"y":2}` {
+		t.Error("FromPairs with duplicate key", string(b))
+	}
+}
+
+func TestNewPair(t *testing.T) {
+	p := NewPair("a", 1)
+	if p.Key() != "a" || p.Value() != 1 {
+		t.Error("NewPair", p.Key(), p.Value())
+	}
+	p.SetValue(2)
+	if p.Value() != 2 {
+		t.Error("SetValue", p.Value())
+	}
+	o := New()
+	o.Set("b", 1)
+	o.Set("a", 2)
+	// sort by value, descending, adjusting values on the way
+	o.Sort(func(a *Pair, b *Pair) bool {
+		return a.Value().(int) > b.Value().(int)
+	})
+	pairs := o.Entries()
+	pairs[0].SetValue(3)
+	o = New()
+	o.SetMany(append(pairs, *NewPair("c", 4)))
+	b, _ := json.Marshal(o)
+	if string(b) != `{"a":3,"b":1,"c":4}` {
+		t.Error("NewPair with SetMany", string(b))
+	}
+}
+
+func TestOrderedMap_SetMany(t *testing.T) {
+	o := New()
+	o.Set("b", 1)
+	o.SetMany([]Pair{{"a", 2}, {"b", 3}, {"c", 4}, {"a", 5}})
+	b, _ := json.Marshal(o)
+	if string(b) != `{"b":3,"a":5,"c":4}` {
+		t.Error("SetMany output", string(b))
+	}
+}
+
+func BenchmarkSetMany(b *testing.B) {
+	pairs := make([]Pair, 100000)
+	for i := range pairs {
+		pairs[i] = Pair{fmt.Sprint(i), i}
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		o := New()
+		o.SetMany(pairs)
+	}
+}
+
+func TestOrderedMap_DeleteMany(t *testing.T) {
+	o := New()
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		o.Set(k, k)
+	}
+	o.DeleteMany("d", "b", "missing", "b")
+	expectedKeys := []string{"a", "c", "e"}
+	k := o.Keys()
+	if len(k) != len(expectedKeys) {
+		t.Fatal("DeleteMany key count", len(k))
+	}
+	for i := range k {
+		if k[i] != expectedKeys[i] {
+			t.Error("DeleteMany key order", i, k[i], "!=", expectedKeys[i])
+		}
+	}
+	if _, ok := o.Get("b"); ok {
+		t.Error("DeleteMany did not remove value")
+	}
+}
+
+func BenchmarkDeleteMany(b *testing.B) {
+	keys := make([]string, 50000)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+	remove := keys[:1000]
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		o := New()
+		for _, k := range keys {
+			o.Set(k, nil)
+		}
+		b.StartTimer()
+		o.DeleteMany(remove...)
+	}
+}
+
+func TestOrderedMap_PickOmit(t *testing.T) {
+	o := New()
+	o.Set("id", 1)
+	o.Set("name", "x")
+	o.Set("password", "secret")
+	o.Set("email", "x@example.com")
+	picked := o.Pick("email", "id", "missing")
+	b, _ := json.Marshal(picked)
+	if string(b) != `{"id":1,"email":"x@example.com"}` {
+		t.Error("Pick output", string(b))
+	}
+	omitted := o.Omit("password", "missing")
+	b, _ = json.Marshal(omitted)
+	if string(b) != `{"id":1,"name":"x","email":"x@example.com"}` {
+		t.Error("Omit output", string(b))
+	}
+	// the derived maps are independent of the original
+	picked.Set("extra", true)
+	if _, ok := o.Get("extra"); ok {
+		t.Error("Pick result shares storage with original")
+	}
+	if len(o.Keys()) != 4 {
+		t.Error("Pick or Omit modified the original")
+	}
+}
+
+func TestOrderedMap_KeysWithPrefix(t *testing.T) {
+	o := New()
+	o.Set("aws.region", "eu")
+	o.Set("gcp.zone", "a")
+	o.Set("aws.key", "k")
+	o.Set("aws", true)
+	if keys := o.KeysWithPrefix("aws."); !reflect.DeepEqual(keys, []string{"aws.region", "aws.key"}) {
+		t.Error("KeysWithPrefix", keys)
+	}
+	if keys := o.KeysWithPrefix("azure."); len(keys) != 0 {
+		t.Error("KeysWithPrefix with no match", keys)
+	}
+	picked := o.PickPrefix("aws.")
+	b, _ := json.Marshal(picked)
+	if string(b) != `{"aws.region":"eu","aws.key":"k"}` {
+		t.Error("PickPrefix", string(b))
+	}
+}
+
+func TestOrderedMap_Filter(t *testing.T) {
+	o := New()
+	o.SetEscapeHTML(false)
+	o.Set("id", 1)
+	o.Set("name", "x")
+	o.Set("internal_id", 7)
+	o.Set("email", nil)
+	filtered := o.Filter(func(key string, v interface{}) bool {
+		return v != nil && !strings.HasPrefix(key, "internal_")
+	})
+	b, _ := json.Marshal(filtered)
+	if string(b) != `{"id":1,"name":"x"}` {
+		t.Error("Filter", string(b))
+	}
+	if filtered.escapeHTML {
+		t.Error("Filter did not keep the settings")
+	}
+	filtered.Set("extra", true)
+	if len(o.Keys()) != 4 {
+		t.Error("Filter modified the original", o.Keys())
+	}
+}
+
+func TestOrderedMap_Partition(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":"x","c":3,"d":"y","e":5}`)
+	calls := 0
+	matched, rest := o.Partition(func(p *Pair) bool {
+		calls++
+		_, ok := p.Value().(string)
+		return ok
+	})
+	b, _ := json.Marshal(matched)
+	if string(b) != `{"b":"x","d":"y"}` {
+		t.Error("Partition matched", string(b))
+	}
+	b, _ = json.Marshal(rest)
+	if string(b) != `{"a":1,"c":3,"e":5}` {
+		t.Error("Partition rest", string(b))
+	}
+	if calls != 5 {
+		t.Error("Partition calls", calls)
+	}
+	matched.Set("z", 1)
+	if _, ok := rest.Get("z"); ok || len(o.Keys()) != 5 {
+		t.Error("Partition results share storage")
+	}
+	var nilMap *OrderedMap
+	matched, rest = nilMap.Partition(nil)
+	if matched.values == nil || rest.values == nil || len(matched.Keys())+len(rest.Keys()) != 0 {
+		t.Error("Partition of nil map", matched.Keys(), rest.Keys())
+	}
+}
+
+func TestOrderedMap_GroupBy(t *testing.T) {
+	o := mustUnmarshal(t, `{"r1":{"team":"b","n":1},"r2":{"team":"a","n":2},"r3":{"team":"b","n":3},"r4":{"n":4}}`)
+	groups := o.GroupBy(func(p *Pair) string {
+		row := p.Value().(OrderedMap)
+		team, _ := row.GetString("team")
+		return team
+	})
+	b, _ := json.Marshal(groups)
+	expected := `{"b":{"r1":{"team":"b","n":1},"r3":{"team":"b","n":3}},` +
+		`"a":{"r2":{"team":"a","n":2}},"":{"r4":{"n":4}}}`
+	if string(b) != expected {
+		t.Error("GroupBy", string(b))
+	}
+	if group, ok := groups.GetOrderedMap("b"); !ok || !reflect.DeepEqual(group.Keys(), []string{"r1", "r3"}) {
+		t.Error("GroupBy group", group.Keys(), ok)
+	}
+	if groups := New().GroupBy(nil); len(groups.Keys()) != 0 {
+		t.Error("GroupBy of an empty map", groups.Keys())
+	}
+	var nilMap *OrderedMap
+	if groups := nilMap.GroupBy(nil); groups.values == nil || len(groups.Keys()) != 0 {
+		t.Error("GroupBy of nil map", groups.Keys())
+	}
+}
+
+func TestOrderedMap_Slice(t *testing.T) {
+	o := New()
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		o.Set(k, k)
+	}
+	tests := []struct {
+		from, to int
+		expected string
+	}{
+		{1, 3, `{"b":"b","c":"c"}`},
+		{0, 5, `{"a":"a","b":"b","c":"c","d":"d","e":"e"}`},
+		{3, 10, `{"d":"d","e":"e"}`},
+		{-2, 1, `{"a":"a"}`},
+		{6, 8, `{}`},
+		{3, 1, `{}`},
+		{0, -1, `{}`},
+		{-3, -1, `{}`},
+	}
+	for _, test := range tests {
+		s := o.Slice(test.from, test.to)
+		b, _ := json.Marshal(s)
+		if string(b) != test.expected {
+			t.Error("Slice", test.from, test.to, string(b))
+		}
+	}
+	s := o.Slice(0, 2)
+	s.Set("z", 1)
+	s.Delete("a")
+	if !reflect.DeepEqual(o.Keys(), []string{"a", "b", "c", "d", "e"}) {
+		t.Error("Slice modified the original", o.Keys())
+	}
+	var nilMap *OrderedMap
+	if s := nilMap.Slice(0, 1); s.values == nil || len(s.Keys()) != 0 {
+		t.Error("Slice of nil map", s.Keys())
+	}
+}
+
+func TestOrderedMap_ValuesOrdered(t *testing.T) {
+	o := New()
+	o.Set("c", 1)
+	o.Set("a", "x")
+	o.Set("b", true)
+	expected := []interface{}{1, "x", true}
+	if values := o.ValuesOrdered(); !reflect.DeepEqual(values, expected) {
+		t.Error("ValuesOrdered", values, "!=", expected)
+	}
+}
+
+func TestOrderedMap_KeysCopy(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	keys := o.KeysCopy()
+	keys[0] = "changed"
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	if k := o.Keys(); k[0] != "a" || k[1] != "b" {
+		t.Error("Modifying KeysCopy changed the map", k)
+	}
+}
+
+func TestOrderedMap_Append(t *testing.T) {
+	o := New()
+	o.Append("a", 1)
+	o.Append("b", 2)
+	o.Set("a", 3)
+	b, _ := json.Marshal(o)
+	if string(b) != `{"a":3,"b":2}` {
+		t.Error("Append output", string(b))
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o := New()
+		for _, k := range keys {
+			o.Set(k, nil)
+		}
+	}
+}
+
+func BenchmarkAppend(b *testing.B) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o := New()
+		for _, k := range keys {
+			o.Append(k, nil)
+		}
+	}
+}
+
+func TestOrderedMap_SetMaxDepth(t *testing.T) {
+	o := New()
+	o.SetMaxDepth(3)
+	if err := json.Unmarshal([]byte(`{"a":{"b":[1]}}`), o); err != nil {
+		t.Error("Unmarshal within max depth", err)
+	}
+	if err := json.Unmarshal([]byte(`{"a":{"b":[{}]}}`), o); err == nil {
+		t.Error("Expected error for input exceeding max depth")
+	}
+	// deep input is decoded without recursion
+	depth := 2000
+	s := `{"a":` + strings.Repeat(`[{"b":`, depth) + `1` + strings.Repeat(`}]`, depth) + `}`
+	o = New()
+	if err := json.Unmarshal([]byte(s), o); err != nil {
+		t.Fatal("Unmarshal deep input", err)
+	}
+	b, _ := json.Marshal(o)
+	if string(b) != s {
+		t.Error("Deep input does not round trip")
+	}
+}
+
+func TestUnmarshalJSONDuplicateKeysTypedArrays(t *testing.T) {
+	s := `{"b":[{"x":[]}],"b":[[1]],"c":["x"],"c":[1]}`
+	o := New()
+	o.SetTypedArrays(true)
+	if err := json.Unmarshal([]byte(s), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if v, _ := o.Get("b"); !reflect.DeepEqual(v, []interface{}{[]float64{1}}) {
+		t.Errorf("Duplicate key with typed arrays: %#v", v)
+	}
+	if v, _ := o.Get("c"); !reflect.DeepEqual(v, []float64{1}) {
+		t.Errorf("Duplicate key with typed arrays: %#v", v)
+	}
+}
+
+func TestForEachPair(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	o.Set("c", 3)
+	var keys []string
+	o.ForEachPair(func(p Pair) bool {
+		keys = append(keys, p.Key())
+		return p.Value() != 2
+	})
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Error("ForEachPair", keys)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		n := 0
+		o.ForEachPair(func(p Pair) bool {
+			n += len(p.key)
+			return true
+		})
+	})
+	if allocs != 0 {
+		t.Error("ForEachPair allocations", allocs)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	o := New()
+	o.Set("a", 1)
+	o.Set("b", 2)
+	o.Set("c", 3)
+	var keys []string
+	var sum int
+	o.ForEach(func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		sum += value.(int)
+		return key != "b"
+	})
+	if !reflect.DeepEqual(keys, []string{"a", "b"}) || sum != 3 {
+		t.Error("ForEach", keys, sum)
+	}
+	var nilMap *OrderedMap
+	nilMap.ForEach(func(string, interface{}) bool {
+		t.Error("ForEach over a nil map")
+		return true
+	})
+}
+
+func TestReduce(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":2,"c":3}`)
+	sum := o.Reduce(0.0, func(acc interface{}, p *Pair) interface{} {
+		return acc.(float64) + p.Value().(float64)
+	})
+	if sum != 6.0 {
+		t.Error("Reduce sum", sum)
+	}
+	joined := o.Reduce("", func(acc interface{}, p *Pair) interface{} {
+		return acc.(string) + p.Key()
+	})
+	if joined != "abc" {
+		t.Error("Reduce concatenation", joined)
+	}
+	index := o.Reduce(map[float64]string{}, func(acc interface{}, p *Pair) interface{} {
+		acc.(map[float64]string)[p.Value().(float64)] = p.Key()
+		return acc
+	})
+	if !reflect.DeepEqual(index, map[float64]string{1: "a", 2: "b", 3: "c"}) {
+		t.Error("Reduce index", index)
+	}
+	if v := New().Reduce("initial", nil); v != "initial" {
+		t.Error("Reduce of an empty map", v)
+	}
+}
+
+func TestAnyAllFind(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":"x","c":3,"d":"y"}`)
+	isString := func(p *Pair) bool {
+		_, ok := p.Value().(string)
+		return ok
+	}
+	var visited []string
+	isNumber := func(p *Pair) bool {
+		visited = append(visited, p.Key())
+		_, ok := p.Value().(float64)
+		return ok
+	}
+	if !o.Any(isString) || o.All(isString) {
+		t.Error("Any or All of strings")
+	}
+	if o.All(isNumber) || !reflect.DeepEqual(visited, []string{"a", "b"}) {
+		t.Error("All stops at the first failure", visited)
+	}
+	p, ok := o.Find(isString)
+	if !ok || p.Key() != "b" || p.Value() != "x" {
+		t.Error("Find", p, ok)
+	}
+	if p, ok := o.Find(func(p *Pair) bool { return p.Value() == nil }); ok || p != nil {
+		t.Error("Find with no match", p, ok)
+	}
+	empty := New()
+	if empty.Any(isString) || !empty.All(isString) {
+		t.Error("Any or All of an empty map")
+	}
+}
+
+func BenchmarkForEachPair(b *testing.B) {
+	o := New()
+	for i := 0; i < 1000; i++ {
+		o.Set(fmt.Sprint(i), i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := 0
+		o.ForEachPair(func(p Pair) bool {
+			n += len(p.key)
+			return true
+		})
+	}
+}
+
+func TestSortKeysRecursive(t *testing.T) {
+	o := New()
+	if err := json.Unmarshal([]byte(`{"b":{"d":1,"c":[{"f":1,"e":2}]},"a":[[{"h":1,"g":2}]]}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	o.SortKeysRecursive(sort.Strings)
+	b, _ := json.Marshal(o)
+	expected := `{"a":[[{"g":2,"h":1}]],"b":{"c":[{"e":2,"f":1}],"d":1}}`
+	if string(b) != expected {
+		t.Error("SortKeysRecursive", string(b))
+	}
+}
+
+func TestNilAndZeroOrderedMap(t *testing.T) {
+	var nilMap *OrderedMap
+	if v, ok := nilMap.Get("a"); v != nil || ok {
+		t.Error("Get on nil map", v, ok)
+	}
+	if len(nilMap.Keys()) != 0 || len(nilMap.KeysCopy()) != 0 || len(nilMap.Values()) != 0 ||
+		len(nilMap.ValuesOrdered()) != 0 || len(nilMap.Entries()) != 0 {
+		t.Error("reading nil map is not empty")
+	}
+	if nilMap.Oldest() != nil || nilMap.Newest() != nil || nilMap.PopFront() != nil || nilMap.PopBack() != nil {
+		t.Error("nil map has entries")
+	}
+	nilMap.ForEachPair(func(Pair) bool {
+		t.Error("ForEachPair on nil map")
+		return true
+	})
+	if v, isNull, present := nilMap.Lookup("a"); v != nil || isNull || present || nilMap.IsNull("a") {
+		t.Error("Lookup on nil map", v, isNull, present)
+	}
+	if raw, ok := nilMap.GetRaw("a"); raw != nil || ok {
+		t.Error("GetRaw on nil map", raw, ok)
+	}
+	if len(nilMap.ExplicitNulls()) != 0 || len(nilMap.ToMap()) != 0 || nilMap.SumNumeric() != 0 {
+		t.Error("reading nil map is not empty")
+	}
+	if err := nilMap.Walk(func([]string, interface{}) error {
+		t.Error("Walk on nil map")
+		return nil
+	}); err != nil {
+		t.Error("Walk on nil map", err)
+	}
+	if err := nilMap.RenameKey("a", "b"); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("RenameKey on nil map", err)
+	}
+	for _, m := range []OrderedMap{nilMap.Pick("a"), nilMap.Omit("a")} {
+		if m.values == nil || len(m.Keys()) != 0 {
+			t.Error("Pick or Omit on nil map", m.Keys())
+		}
+		m.Set("a", 1)
+	}
+	if keys := nilMap.KeysWithPrefix("a"); keys == nil || len(keys) != 0 {
+		t.Error("KeysWithPrefix on nil map", keys)
+	}
+	for _, m := range []OrderedMap{nilMap.PickPrefix("a"), nilMap.Filter(nil)} {
+		if m.values == nil || len(m.Keys()) != 0 {
+			t.Error("derived map of nil map", m.Keys())
+		}
+	}
+	if p, ok := nilMap.Provenance("a"); ok || p != (Provenance{}) {
+		t.Error("Provenance on nil map", p, ok)
+	}
+	if nilMap.DirtyKeys() != nil || nilMap.Check() != nil {
+		t.Error("nil map has changes or violations")
+	}
+	if nilMap.OrderFingerprint() != New().OrderFingerprint() ||
+		nilMap.DeepOrderFingerprint() != New().DeepOrderFingerprint() {
+		t.Error("nil map fingerprint differs from an empty map")
+	}
+	if s := nilMap.ToGoLiteral(); s != "orderedmap.New()" {
+		t.Error("ToGoLiteral on nil map", s)
+	}
+	for _, m := range []OrderedMap{nilMap.CloneMapped(nil), nilMap.AggregateBy(strings.ToLower),
+		nilMap.MergeNumeric(nil, nil)} {
+		if m.values == nil || len(m.Keys()) != 0 {
+			t.Error("derived map of nil map", m.Keys())
+		}
+	}
+	other := New()
+	other.Set("a", 1)
+	if m := nilMap.MergeNumeric(other, nil); !reflect.DeepEqual(m.Keys(), []string{"a"}) {
+		t.Error("MergeNumeric into nil map", m.Keys())
+	}
+	var target int
+	if err := nilMap.DecodeKey("a", &target); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("DecodeKey on nil map", err)
+	}
+	var buf strings.Builder
+	if contentType, err := nilMap.WriteMultipart(&buf); err != nil || !strings.HasPrefix(contentType, "multipart/form-data") {
+		t.Error("WriteMultipart on nil map", contentType, err)
+	}
+	signed, err := nilMap.SignedMarshal(func(canonical []byte) ([]byte, error) {
+		if string(canonical) != "{}" {
+			t.Error("SignedMarshal of nil map signed", string(canonical))
+		}
+		return []byte("s"), nil
+	}, "sig")
+	if err != nil || string(signed) != `{"sig":"cw=="}` {
+		t.Error("SignedMarshal on nil map", string(signed), err)
+	}
+	nilMap.Delete("a")
+	nilMap.DeleteMany("a", "b")
+	func() {
+		defer func() {
+			if r := recover(); r != "orderedmap: assignment to entry in nil *OrderedMap" {
+				t.Error("Set on nil map", r)
+			}
+		}()
+		nilMap.Set("a", 1)
+	}()
+
+	var zero OrderedMap
+	zero.Set("a", 1)
+	zero.Append("b", 2)
+	zero.SetMany([]Pair{{"c", 3}})
+	b, _ := json.Marshal(zero)
+	if string(b) != `{"a":1,"b":2,"c":3}` {
+		t.Error("Set on zero map", string(b))
+	}
+
+	var s struct {
+		Value   OrderedMap
+		Pointer *OrderedMap
+		Missing *OrderedMap
+	}
+	if err := json.Unmarshal([]byte(`{"Value":{"b":1,"a":2},"Pointer":{"d":3,"c":4}}`), &s); err != nil {
+		t.Fatal("Unmarshal struct", err)
+	}
+	s.Value.Set("e", 5)
+	s.Pointer.Set("f", 6)
+	if !reflect.DeepEqual(s.Value.Keys(), []string{"b", "a", "e"}) ||
+		!reflect.DeepEqual(s.Pointer.Keys(), []string{"d", "c", "f"}) || s.Missing.Keys() != nil {
+		t.Error("Unmarshal struct", s.Value.Keys(), s.Pointer.Keys(), s.Missing.Keys())
+	}
+	b, _ = json.Marshal(s)
+	if string(b) != `{"Value":{"b":1,"a":2,"e":5},"Pointer":{"d":3,"c":4,"f":6},"Missing":null}` {
+		t.Error("Marshal struct", string(b))
+	}
+}
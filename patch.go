@@ -0,0 +1,147 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTestFailed is returned by ApplyPatch when a test operation finds a
+// value other than the expected one.
+var ErrTestFailed = errors.New("orderedmap: patch test failed")
+
+// patchOperation is an operation of a JSON Patch document.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyPatch applies a JSON Patch document (RFC 6902), an array of add,
+// remove, replace, move, copy and test operations, to the map. A value
+// added to a map replaces the value of an existing key in its position or
+// is added at the end, and a value added to an array is inserted at its
+// index, or appended for the index "-". Moving a key of a map to itself
+// moves it to the end of the map. Objects in the patch are decoded as
+// OrderedMaps, keeping their order. The patch is applied to a copy of the
+// map, so if any operation fails the map is left unchanged.
+func (o *OrderedMap) ApplyPatch(patch []byte) error {
+	var ops []patchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return fmt.Errorf("orderedmap: invalid patch: %w", err)
+	}
+	c := o.CloneMapped(nil)
+	for i, op := range ops {
+		if err := c.applyOperation(op); err != nil {
+			return fmt.Errorf("orderedmap: patch operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+	o.keys, o.values = c.keys, c.values
+	o.raw, o.provenance, o.nulls, o.dirty = c.raw, c.provenance, c.nulls, c.dirty
+	return nil
+}
+
+func (o *OrderedMap) applyOperation(op patchOperation) error {
+	if op.Path == nil {
+		return errors.New("missing path")
+	}
+	path := *op.Path
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return errors.New("missing value")
+		}
+		var err error
+		if value, err = unmarshalValue(op.Value); err != nil {
+			return err
+		}
+	case "move", "copy":
+		if op.From == nil {
+			return errors.New("missing from")
+		}
+		from, err := o.GetPointer(*op.From)
+		if err != nil {
+			return err
+		}
+		if op.Op == "copy" {
+			value = cloneMapped(from, nil)
+			break
+		}
+		if *op.From == "" && path == "" {
+			return nil
+		}
+		if strings.HasPrefix(path, *op.From+"/") {
+			return fmt.Errorf("cannot move %s into itself", *op.From)
+		}
+		if err := o.DeletePointer(*op.From); err != nil {
+			return err
+		}
+		value = from
+	}
+	switch op.Op {
+	case "add", "move", "copy":
+		return o.addPointer(path, value)
+	case "remove":
+		return o.DeletePointer(path)
+	case "replace":
+		if _, err := o.GetPointer(path); err != nil {
+			return err
+		}
+		if path == "" {
+			return o.replaceRoot(value)
+		}
+		return o.SetPointer(path, value)
+	case "test":
+		current, err := o.GetPointer(path)
+		if err != nil {
+			return err
+		}
+		if !valuesEqual(current, value, false) {
+			return fmt.Errorf("%w: %s", ErrTestFailed, path)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// addPointer adds value at pointer as the add operation of JSON Patch does.
+func (o *OrderedMap) addPointer(pointer string, value interface{}) error {
+	if pointer == "" {
+		return o.replaceRoot(value)
+	}
+	return o.updatePointer(pointer, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case *OrderedMap:
+			p.Set(token, value)
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		}
+		return nil, errNotContainer
+	})
+}
+
+// replaceRoot replaces the keys and values of o with those of value, which
+// must be a map.
+func (o *OrderedMap) replaceRoot(value interface{}) error {
+	m, ok := asMap(value)
+	if !ok {
+		return errors.New("the root of the document must be an object")
+	}
+	keys := append([]string(nil), m.keys...)
+	o.DeleteMany(o.KeysCopy()...)
+	for _, k := range keys {
+		o.Set(k, m.values[k])
+	}
+	return nil
+}
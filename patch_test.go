@@ -0,0 +1,79 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct{ doc, patch, expected string }{
+		// examples from RFC 6902 appendix A
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			`{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		// adding an existing key keeps its position
+		{`{"a":1,"b":2}`, `[{"op":"add","path":"/a","value":{"z":1,"y":2}}]`, `{"a":{"z":1,"y":2},"b":2}`},
+		{`{"a":{"x":[1]}}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"add","path":"/b/x/0","value":0}]`,
+			`{"a":{"x":[1]},"b":{"x":[0,1]}}`},
+		{`{"a":{"y":2,"x":1}}`, `[{"op":"test","path":"","value":{"a":{"x":1,"y":2}}}]`, `{"a":{"y":2,"x":1}}`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+		{`{"a":1,"b":2}`, `[{"op":"move","from":"/a","path":"/a"}]`, `{"b":2,"a":1}`},
+		{`{"a":[1,2]}`, `[{"op":"move","from":"/a/0","path":"/a/0"}]`, `{"a":[1,2]}`},
+	}
+	for _, test := range tests {
+		o := mustUnmarshal(t, test.doc)
+		if err := o.ApplyPatch([]byte(test.patch)); err != nil {
+			t.Error("ApplyPatch", test.patch, err)
+			continue
+		}
+		b, _ := json.Marshal(o)
+		if string(b) != test.expected {
+			t.Error("ApplyPatch", test.patch, string(b))
+		}
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	doc := `{"foo":{"bar":[1,2]},"baz":"qux"}`
+	tests := []string{
+		`[{"op":"add","path":"/missing/x","value":1}]`,
+		`[{"op":"add","path":"/foo/bar/3","value":1}]`,
+		`[{"op":"add","path":"/foo"}]`,
+		`[{"op":"remove","path":"/nope"}]`,
+		`[{"op":"replace","path":"/foo/bar/2","value":1}]`,
+		`[{"op":"move","from":"/foo","path":"/foo/x"}]`,
+		`[{"op":"copy","path":"/x"}]`,
+		`[{"op":"jump","path":"/foo"}]`,
+		`[{"op":"remove"}]`,
+		`[{"op":"add","path":"","value":[1]}]`,
+		`{"op":"add"}`,
+		// the first operation is undone when the second fails
+		`[{"op":"remove","path":"/baz"},{"op":"test","path":"/foo/bar/0","value":2}]`,
+	}
+	for _, patch := range tests {
+		o := mustUnmarshal(t, doc)
+		if err := o.ApplyPatch([]byte(patch)); err == nil {
+			t.Error("ApplyPatch did not fail", patch)
+		}
+		if b, _ := json.Marshal(o); string(b) != doc {
+			t.Error("failed ApplyPatch changed the map", patch, string(b))
+		}
+	}
+	o := mustUnmarshal(t, doc)
+	err := o.ApplyPatch([]byte(`[{"op":"test","path":"/baz","value":"x"}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Error("ApplyPatch test error", err)
+	}
+}
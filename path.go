@@ -0,0 +1,217 @@
+package orderedmap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GetPath returns the value at path, where each element of path is a key
+// in a nested map, eg o.GetPath("spec", "template", "metadata"). It returns
+// false if a key is missing or an intermediate value is not a map. An empty
+// path returns the map itself.
+func (o *OrderedMap) GetPath(path ...string) (interface{}, bool) {
+	var v interface{} = o
+	for _, key := range path {
+		m, ok := asMap(v)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m.Get(key); !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// SetPath sets the value at path, where each element of path but the last
+// is a key of a nested map, which must already exist unless SetCreatePaths
+// is on. The last key is set as by Set.
+func (o *OrderedMap) SetPath(path []string, value interface{}) error {
+	return o.updatePath(path, o.createPaths, func(m *OrderedMap, key string) (bool, error) {
+		m.Set(key, value)
+		return true, nil
+	})
+}
+
+// SetCreatePaths sets whether SetPath and SetDotted create missing
+// intermediate maps, with the settings of the map they are added to, rather
+// than returning ErrKeyNotFound. Existing values that are not maps are
+// never replaced.
+func (o *OrderedMap) SetCreatePaths(on bool) {
+	o.createPaths = on
+}
+
+// DeletePath removes the value at path, where each element of path is a key
+// in a nested map. Missing keys are ignored.
+func (o *OrderedMap) DeletePath(path ...string) {
+	if o == nil {
+		return
+	}
+	o.updatePath(path, false, func(m *OrderedMap, key string) (bool, error) {
+		if _, ok := m.values[m.canonicalKey(key)]; !ok {
+			return false, nil
+		}
+		m.Delete(key)
+		return true, nil
+	})
+}
+
+// asMap returns v as a map if it is an OrderedMap or a non-nil *OrderedMap.
+func asMap(v interface{}) (*OrderedMap, bool) {
+	switch m := v.(type) {
+	case OrderedMap:
+		return &m, true
+	case *OrderedMap:
+		return m, m != nil
+	}
+	return nil, false
+}
+
+// updatePath calls fn with the map containing the last key of path and
+// that key, creating missing maps on the way if create is set. fn reports
+// whether it changed the map. Nested maps stored by value are stored again
+// if they changed, so that changes to their keys are kept, and maps created
+// on the way are stored. Maps supplied by a missing key handler are not.
+func (o *OrderedMap) updatePath(path []string, create bool, fn func(m *OrderedMap, key string) (bool, error)) error {
+	if len(path) == 0 {
+		return fmt.Errorf("orderedmap: empty path")
+	}
+	_, err := o.updatePathFrom(path, 0, create, fn)
+	return err
+}
+
+// updatePathFrom is updatePath from the key at index i of path. It reports
+// whether o changed.
+func (o *OrderedMap) updatePathFrom(path []string, i int, create bool, fn func(*OrderedMap, string) (bool, error)) (bool, error) {
+	if i == len(path)-1 {
+		return fn(o, path[i])
+	}
+	v, stored := o.values[o.canonicalKey(path[i])]
+	created := false
+	if !stored {
+		var ok bool
+		if v, ok = o.Get(path[i]); !ok {
+			if !create {
+				return false, fmt.Errorf("%w: %s", ErrKeyNotFound, strings.Join(path[:i+1], "/"))
+			}
+			v, created = o.subMap(func(string) bool { return false }), true
+		}
+	}
+	switch child := v.(type) {
+	case OrderedMap:
+		changed, err := child.updatePathFrom(path, i+1, create, fn)
+		if err != nil {
+			return false, err
+		}
+		if created || stored && changed {
+			o.Set(path[i], child)
+			return true, nil
+		}
+		return false, nil
+	case *OrderedMap:
+		if child != nil {
+			// the pointer itself is unchanged
+			_, err := child.updatePathFrom(path, i+1, create, fn)
+			return false, err
+		}
+	}
+	return false, fmt.Errorf("orderedmap: %s is not a map", strings.Join(path[:i+1], "/"))
+}
+
+// GetDotted returns the value at a path of keys separated by dots, eg
+// o.GetDotted("server.tls.cert"), as GetPath does. A dot or backslash that
+// is part of a key is escaped with a backslash, so `a\.b.c` is the path
+// "a.b", "c".
+func (o *OrderedMap) GetDotted(path string) (interface{}, bool) {
+	return o.GetPath(splitDotted(path)...)
+}
+
+// SetDotted sets the value at a dotted path, as SetPath does. See GetDotted
+// for the path syntax.
+func (o *OrderedMap) SetDotted(path string, value interface{}) error {
+	return o.SetPath(splitDotted(path), value)
+}
+
+// GetByPath returns the value at a dotted path, as GetDotted does, except
+// that a segment applied to an array is an index into it, eg
+// o.GetByPath("items.3.name"). It returns false if a key is missing, an
+// index is out of range or a value on the path is neither a map nor an
+// array.
+func (o *OrderedMap) GetByPath(path string) (interface{}, bool) {
+	var v interface{} = o
+	for _, key := range splitDotted(path) {
+		if m, ok := asMap(v); ok {
+			if v, ok = m.Get(key); !ok {
+				return nil, false
+			}
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return nil, false
+		}
+		i, err := arrayIndex(key, rv.Len(), false)
+		if err != nil {
+			return nil, false
+		}
+		v = rv.Index(i).Interface()
+	}
+	return v, true
+}
+
+// FindPath returns the values at a dotted path, as GetByPath does, in which
+// a segment "*" matches any key or array index and a segment "**" matches
+// any number of keys and indices, including none. Matches are returned in
+// document order, eg o.FindPath("spec.containers.*.image") returns the image
+// of every container and o.FindPath("**.image") every image in the map.
+func (o *OrderedMap) FindPath(pattern string) []PathValue {
+	segments := splitDotted(pattern)
+	var found []PathValue
+	o.Walk(func(path []string, value interface{}) error {
+		if matchSegments(segments, path) {
+			found = append(found, PathValue{path, value})
+		}
+		return nil
+	})
+	return found
+}
+
+// matchSegments reports whether path matches the pattern segments, which
+// may contain "*" and "**" wildcards.
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 || (pattern[0] != "*" && pattern[0] != path[0]) {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// splitDotted splits a dotted path into keys, removing escapes.
+func splitDotted(path string) []string {
+	var keys []string
+	var key strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; {
+		case c == '\\' && i+1 < len(path):
+			i++
+			key.WriteByte(path[i])
+		case c == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(c)
+		}
+	}
+	return append(keys, key.String())
+}
@@ -0,0 +1,211 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetPath(t *testing.T) {
+	o := mustUnmarshal(t, `{"spec":{"template":{"metadata":{"name":"x"}},"list":[1]}}`)
+	p := New()
+	p.Set("name", "y")
+	o.Set("ptr", p)
+	tests := []struct {
+		path  []string
+		value interface{}
+		ok    bool
+	}{
+		{[]string{"spec", "template", "metadata", "name"}, "x", true},
+		{[]string{"ptr", "name"}, "y", true},
+		{[]string{"spec", "missing"}, nil, false},
+		{[]string{"spec", "list", "0"}, nil, false},
+	}
+	for _, test := range tests {
+		v, ok := o.GetPath(test.path...)
+		if v != test.value || ok != test.ok {
+			t.Error("GetPath", test.path, v, ok)
+		}
+	}
+	if v, ok := o.GetPath(); v != &o || !ok {
+		t.Error("GetPath with empty path", v, ok)
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":{"b":{"c":1}},"d":2}`)
+	p := New()
+	o.Set("p", p)
+	if err := o.SetPath([]string{"a", "b", "e"}, 3); err != nil {
+		t.Error("SetPath", err)
+	}
+	if err := o.SetPath([]string{"a", "b", "c"}, 4); err != nil {
+		t.Error("SetPath", err)
+	}
+	if err := o.SetPath([]string{"p", "x"}, 5); err != nil {
+		t.Error("SetPath", err)
+	}
+	if err := o.SetPath([]string{"a", "missing", "x"}, 1); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("SetPath with missing key", err)
+	}
+	if err := o.SetPath([]string{"d", "x"}, 1); err == nil || err.Error() != "orderedmap: d is not a map" {
+		t.Error("SetPath through a non-map", err)
+	}
+	if err := o.SetPath(nil, 1); err == nil {
+		t.Error("SetPath with empty path did not fail")
+	}
+	o.DeletePath("a", "b", "c")
+	o.DeletePath("a", "missing", "c")
+	b, _ := json.Marshal(o)
+	if string(b) != `{"a":{"b":{"e":3}},"d":2,"p":{"x":5}}` {
+		t.Error("SetPath and DeletePath", string(b))
+	}
+}
+
+func TestUpdatePathWriteBack(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":{"b":1}}`)
+	o.SetMissingHandler(func(key string) (interface{}, bool) {
+		return *New(), true
+	})
+	o.DeletePath("ghost", "x")
+	if err := o.SetPath([]string{"ghost", "x"}, 1); err != nil {
+		t.Error("SetPath through a handler value", err)
+	}
+	if !reflect.DeepEqual(o.Keys(), []string{"a"}) {
+		t.Error("handler value stored", o.Keys())
+	}
+	// nothing is stored again if nothing changed
+	o.SetTrackChanges(true)
+	o.DeletePath("a", "missing")
+	if changes := o.DirtyKeys(); len(changes) != 0 {
+		t.Error("DeletePath of a missing key changed the map", changes)
+	}
+	o.DeletePath("a", "b")
+	if changes := o.DirtyKeys(); !reflect.DeepEqual(changes, []KeyChange{{"a", ChangeModified}}) {
+		t.Error("DeletePath", changes)
+	}
+	var nilMap *OrderedMap
+	nilMap.DeletePath("a", "b")
+}
+
+func TestGetDotted(t *testing.T) {
+	o := mustUnmarshal(t, `{"server":{"tls":{"cert":"c"},"a.b":{"c":1},"x\\y":2}}`)
+	tests := []struct {
+		path  string
+		value interface{}
+		ok    bool
+	}{
+		{"server.tls.cert", "c", true},
+		{`server.a\.b.c`, float64(1), true},
+		{`server.x\\y`, float64(2), true},
+		{"server.a.b.c", nil, false},
+		{"server.tls.missing", nil, false},
+	}
+	for _, test := range tests {
+		v, ok := o.GetDotted(test.path)
+		if v != test.value || ok != test.ok {
+			t.Error("GetDotted", test.path, v, ok)
+		}
+	}
+	if err := o.SetDotted(`server.tls.key\.pem`, "k"); err != nil {
+		t.Error("SetDotted", err)
+	}
+	if v, _ := o.GetPath("server", "tls", "key.pem"); v != "k" {
+		t.Error("SetDotted", v)
+	}
+	splits := map[string][]string{
+		"":       {""},
+		"a":      {"a"},
+		"a..b":   {"a", "", "b"},
+		`a\`:     {`a\`},
+		`\.\\.x`: {`.\`, "x"},
+	}
+	for path, expected := range splits {
+		if keys := splitDotted(path); !reflect.DeepEqual(keys, expected) {
+			t.Error("splitDotted", path, keys)
+		}
+	}
+}
+
+func TestSetCreatePaths(t *testing.T) {
+	o := New()
+	o.SetCreatePaths(true)
+	o.Set("x", 1)
+	if err := o.SetPath([]string{"a", "b", "c"}, 1); err != nil {
+		t.Error("SetPath", err)
+	}
+	if err := o.SetDotted("a.b.d", 2); err != nil {
+		t.Error("SetDotted", err)
+	}
+	if err := o.SetDotted("a.e", 3); err != nil {
+		t.Error("SetDotted", err)
+	}
+	if err := o.SetDotted("x.y", 4); err == nil {
+		t.Error("SetDotted through a non-map did not fail")
+	}
+	b, _ := json.Marshal(o)
+	if string(b) != `{"x":1,"a":{"b":{"c":1,"d":2},"e":3}}` {
+		t.Error("SetCreatePaths", string(b))
+	}
+	// created maps have the settings of their parent
+	a, _ := o.GetOrderedMap("a")
+	if !a.createPaths || !a.escapeHTML {
+		t.Error("SetCreatePaths settings", a.createPaths, a.escapeHTML)
+	}
+}
+
+func TestGetByPath(t *testing.T) {
+	o := mustUnmarshal(t, `{"items":[{"name":"a"},{"name":"b","tags":["x","y"]}],"1":{"name":"key"}}`)
+	o.Set("typed", []string{"p", "q"})
+	tests := []struct {
+		path  string
+		value interface{}
+		ok    bool
+	}{
+		{"items.1.name", "b", true},
+		{"items.1.tags.0", "x", true},
+		{"1.name", "key", true},
+		{"typed.1", "q", true},
+		{"items.2.name", nil, false},
+		{"items.-1", nil, false},
+		{"items.01.name", nil, false},
+		{"items.name", nil, false},
+		{"items.0.name.0", nil, false},
+	}
+	for _, test := range tests {
+		v, ok := o.GetByPath(test.path)
+		if v != test.value || ok != test.ok {
+			t.Error("GetByPath", test.path, v, ok)
+		}
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	o := mustUnmarshal(t, `{"spec":{"containers":[{"name":"a","image":"x"},{"name":"b","image":"y"}],`+
+		`"init":{"image":"z"}},"image":"top"}`)
+	tests := map[string][]string{
+		"spec.containers.*.image": {"spec.containers.0.image=x", "spec.containers.1.image=y"},
+		"spec.*.image":            {"spec.init.image=z"},
+		"**.image": {"spec.containers.0.image=x", "spec.containers.1.image=y",
+			"spec.init.image=z", "image=top"},
+		"spec.**.name":      {"spec.containers.0.name=a", "spec.containers.1.name=b"},
+		"spec.containers.1": {"spec.containers.1=map[image:y name:b]"},
+		"**.**.init.image":  {"spec.init.image=z"},
+		"spec.missing.*":    nil,
+	}
+	for pattern, expected := range tests {
+		var found []string
+		for _, pv := range o.FindPath(pattern) {
+			if m, ok := asMap(pv.Value); ok {
+				pv.Value = m.ToMap()
+			}
+			found = append(found, fmt.Sprint(strings.Join(pv.Path, "."), "=", pv.Value))
+		}
+		if !reflect.DeepEqual(found, expected) {
+			t.Error("FindPath", pattern, found)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetPointer returns the value at a JSON Pointer (RFC 6901), such as
+// "/a/b/0/c", in which "~1" stands for "/" and "~0" for "~" in keys and
+// numbers index into arrays. The empty pointer refers to the map itself.
+func (o *OrderedMap) GetPointer(pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{} = o
+	for i, token := range tokens {
+		next, ok := pointerChild(v, token)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, joinPointer(tokens[:i+1]))
+		}
+		v = next
+	}
+	return v, nil
+}
+
+// SetPointer sets the value at a JSON Pointer. The parent of the value must
+// exist. A key of a map is set as by Set. An index into an array must be
+// in range or equal to its length, which appends value, as does the index
+// "-".
+func (o *OrderedMap) SetPointer(pointer string, value interface{}) error {
+	return o.updatePointer(pointer, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case *OrderedMap:
+			p.Set(token, value)
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+			if i == len(p) {
+				return append(p, value), nil
+			}
+			p[i] = value
+			return p, nil
+		}
+		return nil, errNotContainer
+	})
+}
+
+// DeletePointer removes the value at a JSON Pointer, removing the key from
+// its map or the element from its array. It returns an error wrapping
+// ErrKeyNotFound if there is no value at the pointer.
+func (o *OrderedMap) DeletePointer(pointer string) error {
+	return o.updatePointer(pointer, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case *OrderedMap:
+			if _, ok := p.values[p.canonicalKey(token)]; !ok {
+				return nil, ErrKeyNotFound
+			}
+			p.Delete(token)
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(p), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:i:i], p[i+1:]...), nil
+		}
+		return nil, errNotContainer
+	})
+}
+
+var errNotContainer = errors.New("parent is not an object or array")
+
+// pointerUpdate changes the parent of the value a pointer refers to and
+// returns the new parent. Maps are passed as *OrderedMap.
+type pointerUpdate func(parent interface{}, token string) (interface{}, error)
+
+// updatePointer applies fn to the parent of the value at pointer, storing
+// any new parents back into their containers.
+func (o *OrderedMap) updatePointer(pointer string, fn pointerUpdate) error {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("orderedmap: cannot change the root of the document")
+	}
+	if _, err := updatePointerValue(o, tokens, 0, fn); err != nil {
+		if err == ErrKeyNotFound {
+			return fmt.Errorf("%w: %s", ErrKeyNotFound, pointer)
+		}
+		return fmt.Errorf("orderedmap: %s: %w", pointer, err)
+	}
+	return nil
+}
+
+func updatePointerValue(v interface{}, tokens []string, i int, fn pointerUpdate) (interface{}, error) {
+	if m, ok := v.(OrderedMap); ok {
+		updated, err := updatePointerValue(&m, tokens, i, fn)
+		if err != nil {
+			return nil, err
+		}
+		return *updated.(*OrderedMap), nil
+	}
+	if i == len(tokens)-1 {
+		return fn(v, tokens[i])
+	}
+	child, ok := pointerChild(v, tokens[i])
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	child, err := updatePointerValue(child, tokens, i+1, fn)
+	if err != nil {
+		return nil, err
+	}
+	switch p := v.(type) {
+	case *OrderedMap:
+		p.Set(tokens[i], child)
+	case []interface{}:
+		index, _ := strconv.Atoi(tokens[i])
+		p[index] = child
+	}
+	return v, nil
+}
+
+// pointerChild returns the value for token in a map or array.
+func pointerChild(v interface{}, token string) (interface{}, bool) {
+	if s, ok := v.([]interface{}); ok {
+		i, err := arrayIndex(token, len(s), false)
+		if err != nil {
+			return nil, false
+		}
+		return s[i], true
+	}
+	if m, ok := asMap(v); ok {
+		return m.Get(token)
+	}
+	return nil, false
+}
+
+// arrayIndex parses an array index token for an array of length n. If
+// appending is set, "-" and n are accepted and mean the end of the array.
+func arrayIndex(token string, n int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return n, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.Trim(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i > n || (i == n && !appending) {
+		return 0, ErrKeyNotFound
+	}
+	return i, nil
+}
+
+// parsePointer splits a JSON Pointer into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("orderedmap: invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// joinPointer returns the JSON Pointer for tokens.
+func joinPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteByte('/')
+		b.WriteString(pointerEscape(token))
+	}
+	return b.String()
+}
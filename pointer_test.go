@@ -0,0 +1,89 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestGetPointer(t *testing.T) {
+	// the examples of RFC 6901
+	o := mustUnmarshal(t, `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8,"o":{"p":[{"q":9}]}}`)
+	tests := map[string]interface{}{
+		"/foo/0":   "bar",
+		"/":        float64(0),
+		"/a~1b":    float64(1),
+		"/c%d":     float64(2),
+		"/e^f":     float64(3),
+		"/g|h":     float64(4),
+		"/i\\j":    float64(5),
+		"/k\"l":    float64(6),
+		"/ ":       float64(7),
+		"/m~0n":    float64(8),
+		"/o/p/0/q": float64(9),
+	}
+	for pointer, expected := range tests {
+		v, err := o.GetPointer(pointer)
+		if err != nil || v != expected {
+			t.Error("GetPointer", pointer, v, err)
+		}
+	}
+	if v, err := o.GetPointer(""); err != nil || v != &o {
+		t.Error("GetPointer root", v, err)
+	}
+	for _, pointer := range []string{"/foo/2", "/foo/-", "/foo/01", "/missing", "/o/p/0/q/r"} {
+		if _, err := o.GetPointer(pointer); !errors.Is(err, ErrKeyNotFound) {
+			t.Error("GetPointer", pointer, err)
+		}
+	}
+	if _, err := o.GetPointer("foo"); err == nil {
+		t.Error("GetPointer without leading slash did not fail")
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":{"b":[1,{"c":2}]},"d":3}`)
+	sets := []struct {
+		pointer string
+		value   interface{}
+	}{
+		{"/a/b/1/c", 20},
+		{"/a/b/1/e", 5},
+		{"/a/b/0", 10},
+		{"/a/b/-", 30},
+		{"/a/b/3", 40},
+		{"/f~1g", 6},
+	}
+	for _, s := range sets {
+		if err := o.SetPointer(s.pointer, s.value); err != nil {
+			t.Error("SetPointer", s.pointer, err)
+		}
+	}
+	b, _ := json.Marshal(o)
+	if string(b) != `{"a":{"b":[10,{"c":20,"e":5},30,40]},"d":3,"f/g":6}` {
+		t.Error("SetPointer", string(b))
+	}
+	for _, pointer := range []string{"/a/b/9", "/missing/x", "/d/x", "", "/a/b/x"} {
+		if err := o.SetPointer(pointer, 1); err == nil {
+			t.Error("SetPointer did not fail", pointer)
+		}
+	}
+
+	for _, pointer := range []string{"/a/b/1/c", "/a/b/0", "/f~1g"} {
+		if err := o.DeletePointer(pointer); err != nil {
+			t.Error("DeletePointer", pointer, err)
+		}
+	}
+	b, _ = json.Marshal(o)
+	if string(b) != `{"a":{"b":[{"e":5},30,40]},"d":3}` {
+		t.Error("DeletePointer", string(b))
+	}
+	for _, pointer := range []string{"/a/b/3", "/a/missing", "/a/b/-"} {
+		if err := o.DeletePointer(pointer); err == nil {
+			t.Error("DeletePointer did not fail", pointer)
+		}
+	}
+	if err := o.DeletePointer("/a/missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("DeletePointer missing key", err)
+	}
+}
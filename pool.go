@@ -0,0 +1,38 @@
+package orderedmap
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which buffers are not returned to
+// the pool, so that one large document does not pin memory indefinitely.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// SetBufferPooling sets whether MarshalJSON reuses buffers and encoder state
+// from earlier calls, which reduces allocations when marshalling often.
+// Pooling is on by default.
+func (o *OrderedMap) SetBufferPooling(on bool) {
+	o.noPool = !on
+}
+
+func newBuffer(pooled bool) *bytes.Buffer {
+	if !pooled {
+		return new(bytes.Buffer)
+	}
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func releaseBuffer(buf *bytes.Buffer, pooled bool) {
+	if pooled && buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
@@ -0,0 +1,58 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestSetBufferPooling(t *testing.T) {
+	for _, pooled := range []bool{true, false} {
+		o := New()
+		o.SetBufferPooling(pooled)
+		o.Set("b", "<x>")
+		o.Set("a", []interface{}{1, map[string]interface{}{"z": 1}})
+		// marshal concurrently to check pooled state is not shared
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					b, err := o.MarshalJSON()
+					if err != nil {
+						t.Error("MarshalJSON", err)
+						return
+					}
+					if string(b) != `{"b":"\u003cx\u003e","a":[1,{"z":1}]}` {
+						t.Error("MarshalJSON pooled output", pooled, string(b))
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	o := New()
+	if err := json.Unmarshal([]byte(goldenInput), o); err != nil {
+		b.Fatal(err)
+	}
+	for _, pooled := range []bool{true, false} {
+		o.SetBufferPooling(pooled)
+		name := "unpooled"
+		if pooled {
+			name = "pooled"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := o.MarshalJSON(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
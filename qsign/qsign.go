@@ -0,0 +1,215 @@
+// Package qsign builds signing base strings from the parameters in an
+// OrderedMap and signs and verifies them, for request signing schemes in
+// which the order of the parameters matters.
+package qsign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/orderedmap"
+)
+
+// ErrInvalidSignature is returned by Verify when a signature does not match.
+var ErrInvalidSignature = errors.New("qsign: invalid signature")
+
+// KeyOrder is the order in which parameters are written to a base string.
+type KeyOrder int
+
+const (
+	// MapOrder writes parameters in the order of the map.
+	MapOrder KeyOrder = iota
+	// SortedOrder writes parameters sorted by key in byte order. The
+	// elements of an array parameter keep their order.
+	SortedOrder
+)
+
+// SignFunc returns the signature of a base string.
+type SignFunc func(base []byte) ([]byte, error)
+
+// HMACSHA256 returns a SignFunc computing the HMAC-SHA256 of the base
+// string with key.
+func HMACSHA256(key []byte) SignFunc {
+	return func(base []byte) ([]byte, error) {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(base)
+		return mac.Sum(nil), nil
+	}
+}
+
+// Canonicalizer writes the parameters in a map as a base string, such as
+// a=1&b=2. The zero value writes keys in map order, separates pairs with
+// "&" and keys from values with "=", and escapes keys and values with
+// EscapeRFC3986.
+type Canonicalizer struct {
+	// PairSeparator separates parameters, "&" if empty.
+	PairSeparator string
+	// KeyValueSeparator separates a key from its value, "=" if empty.
+	KeyValueSeparator string
+	// Escape escapes keys and values, EscapeRFC3986 if nil.
+	Escape func(s string) string
+	// Order is the order of the parameters.
+	Order KeyOrder
+	// Exclude holds keys left out of the base string, such as the key of
+	// the signature itself.
+	Exclude []string
+}
+
+// BaseString returns the base string of the parameters in o. Values are
+// strings, numbers, bools or null, which is written as an empty value. An
+// array is written as one parameter per element with the same key. Nested
+// objects are not supported.
+func (c Canonicalizer) BaseString(o *orderedmap.OrderedMap) (string, error) {
+	pairSep, kvSep, escape := c.PairSeparator, c.KeyValueSeparator, c.Escape
+	if pairSep == "" {
+		pairSep = "&"
+	}
+	if kvSep == "" {
+		kvSep = "="
+	}
+	if escape == nil {
+		escape = EscapeRFC3986
+	}
+	keys := make([]string, 0, len(o.Keys()))
+	for _, k := range o.Keys() {
+		if !c.excluded(k) {
+			keys = append(keys, k)
+		}
+	}
+	if c.Order == SortedOrder {
+		sort.Strings(keys)
+	}
+	var b strings.Builder
+	for _, k := range keys {
+		v, _ := o.Get(k)
+		values := []interface{}{v}
+		if s, ok := v.([]interface{}); ok {
+			values = s
+		}
+		for _, v := range values {
+			s, err := formatValue(v)
+			if err != nil {
+				return "", fmt.Errorf("qsign: %q: %w", k, err)
+			}
+			if b.Len() > 0 {
+				b.WriteString(pairSep)
+			}
+			b.WriteString(escape(k))
+			b.WriteString(kvSep)
+			b.WriteString(escape(s))
+		}
+	}
+	return b.String(), nil
+}
+
+func (c Canonicalizer) excluded(key string) bool {
+	for _, k := range c.Exclude {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the signature of the base string of o.
+func (c Canonicalizer) Sign(o *orderedmap.OrderedMap, sign SignFunc) ([]byte, error) {
+	base, err := c.BaseString(o)
+	if err != nil {
+		return nil, err
+	}
+	return sign([]byte(base))
+}
+
+// Verify checks that sig is the signature of the base string of o,
+// returning ErrInvalidSignature if it is not. Signatures are compared in
+// constant time.
+func (c Canonicalizer) Verify(o *orderedmap.OrderedMap, sig []byte, sign SignFunc) error {
+	expected, err := c.Sign(o, sign)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(sig, expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// formatValue returns the text of a parameter value.
+func formatValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	}
+	return "", fmt.Errorf("unsupported value type %T", v)
+}
+
+// EscapeRFC3986 percent-encodes every byte of s other than the unreserved
+// characters of RFC 3986, letters, digits and "-._~", using upper case hex
+// digits, as required by OAuth 1.0 and similar schemes.
+func EscapeRFC3986(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
+
+// ParseQuery parses a URL query string into a map keeping the order in
+// which keys first appear. A key that appears more than once has an array
+// of its values, in order.
+func ParseQuery(query string) (*orderedmap.OrderedMap, error) {
+	o := orderedmap.New()
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		key, err := url.QueryUnescape(kv[0])
+		if err != nil {
+			return nil, err
+		}
+		value := ""
+		if len(kv) == 2 {
+			if value, err = url.QueryUnescape(kv[1]); err != nil {
+				return nil, err
+			}
+		}
+		switch existing, _ := o.Get(key); e := existing.(type) {
+		case nil:
+			o.Set(key, value)
+		case string:
+			o.Set(key, []interface{}{e, value})
+		case []interface{}:
+			o.Set(key, append(e, value))
+		}
+	}
+	return o, nil
+}
@@ -0,0 +1,70 @@
+package qsign
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/iancoleman/orderedmap"
+)
+
+func TestBaseString(t *testing.T) {
+	o, err := ParseQuery("b=2&a=x+y&tag=1&tag=%C3%A9&empty&sig=abc")
+	if err != nil {
+		t.Fatal("ParseQuery", err)
+	}
+	o.Set("n", 1.5)
+	o.Set("ok", true)
+	tests := []struct {
+		c        Canonicalizer
+		expected string
+	}{
+		{Canonicalizer{Exclude: []string{"sig"}}, "b=2&a=x%20y&tag=1&tag=%C3%A9&empty=&n=1.5&ok=true"},
+		{Canonicalizer{Order: SortedOrder}, "a=x%20y&b=2&empty=&n=1.5&ok=true&sig=abc&tag=1&tag=%C3%A9"},
+		{Canonicalizer{PairSeparator: "\n", KeyValueSeparator: ":", Escape: func(s string) string { return s }, Exclude: []string{"sig", "tag"}},
+			"b:2\na:x y\nempty:\nn:1.5\nok:true"},
+	}
+	for _, test := range tests {
+		base, err := test.c.BaseString(o)
+		if err != nil || base != test.expected {
+			t.Errorf("BaseString %q %v", base, err)
+		}
+	}
+	o.Set("nested", orderedmap.New())
+	if _, err := (Canonicalizer{}).BaseString(o); err == nil {
+		t.Error("BaseString with nested object did not fail")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	sign := HMACSHA256([]byte("secret"))
+	c := Canonicalizer{Exclude: []string{"sig"}}
+	o, _ := ParseQuery("user=1&action=read")
+	sig, err := c.Sign(o, sign)
+	if err != nil {
+		t.Fatal("Sign", err)
+	}
+	o.Set("sig", base64.StdEncoding.EncodeToString(sig))
+	if err := c.Verify(o, sig, sign); err != nil {
+		t.Error("Verify", err)
+	}
+	// reordering the parameters invalidates a signature in map order
+	reordered, _ := ParseQuery("action=read&user=1")
+	if err := c.Verify(reordered, sig, sign); err != ErrInvalidSignature {
+		t.Error("Verify reordered", err)
+	}
+	sorted := Canonicalizer{Order: SortedOrder}
+	sig, _ = sorted.Sign(o, sign)
+	if err := sorted.Verify(reordered, sig, sign); err != ErrInvalidSignature {
+		t.Error("Verify with missing key", err)
+	}
+	reordered.Set("sig", o.Values()["sig"])
+	if err := sorted.Verify(reordered, sig, sign); err != nil {
+		t.Error("Verify sorted", err)
+	}
+}
+
+func TestEscapeRFC3986(t *testing.T) {
+	if s := EscapeRFC3986("a-._~ +/é"); s != "a-._~%20%2B%2F%C3%A9" {
+		t.Error("EscapeRFC3986", s)
+	}
+}
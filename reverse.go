@@ -0,0 +1,26 @@
+//go:build go1.23
+// +build go1.23
+
+package orderedmap
+
+import "iter"
+
+// AllReverse returns an iterator over the keys and values of the map from
+// the newest entry to the oldest. It walks the keys in place rather than
+// copying them, so the map must not be modified during the iteration.
+func (o *OrderedMap) AllReverse() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		if o == nil {
+			return
+		}
+		if o.guard != nil {
+			defer o.guard.endIteration(o, o.guard.begin(), "AllReverse")
+		}
+		for i := len(o.keys) - 1; i >= 0; i-- {
+			key := o.keys[i]
+			if !yield(key, o.values[key]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+//go:build go1.23
+// +build go1.23
+
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllReverse(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":2,"c":3}`)
+	var keys []string
+	var values []interface{}
+	for k, v := range o.AllReverse() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	if !reflect.DeepEqual(keys, []string{"c", "b", "a"}) ||
+		!reflect.DeepEqual(values, []interface{}{float64(3), float64(2), float64(1)}) {
+		t.Error("AllReverse", keys, values)
+	}
+	keys = nil
+	for k := range o.AllReverse() {
+		keys = append(keys, k)
+		break
+	}
+	if !reflect.DeepEqual(keys, []string{"c"}) {
+		t.Error("AllReverse with break", keys)
+	}
+	var nilMap *OrderedMap
+	for range nilMap.AllReverse() {
+		t.Error("AllReverse over a nil map")
+	}
+}
+
+func TestAllReverseDebug(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"b":2}`)
+	o.SetDebug(true)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("AllReverse with a removed key did not panic")
+		}
+	}()
+	for k := range o.AllReverse() {
+		o.Delete(k)
+	}
+}
@@ -0,0 +1,104 @@
+// Package roundtriptest checks that JSON documents keep their key order
+// when decoded into an OrderedMap and encoded again, so that projects can
+// assert the ordering guarantees they rely on over their own fixtures.
+package roundtriptest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/iancoleman/orderedmap"
+)
+
+// Option configures the map a document is decoded into, eg
+// (*orderedmap.OrderedMap).SetBigNumbers with its argument bound.
+type Option func(o *orderedmap.OrderedMap)
+
+// Error describes a document that did not survive a round trip.
+type Error struct {
+	// DuplicateKeys holds the JSON Pointers of repeated keys in the
+	// document, only the last of which is kept.
+	DuplicateKeys []string
+	// Mismatches holds the differences between the document and its
+	// encoding, as reported by orderedmap.StrictOrderEqual.
+	Mismatches []orderedmap.Mismatch
+	// Unstable is set if encoding the decoded encoding again produced
+	// different bytes.
+	Unstable bool
+}
+
+func (e *Error) Error() string {
+	if e.Unstable {
+		return "roundtriptest: encoding is not stable"
+	}
+	if len(e.DuplicateKeys) > 0 {
+		return "roundtriptest: duplicate keys " + strings.Join(e.DuplicateKeys, ", ")
+	}
+	s := make([]string, len(e.Mismatches))
+	for i, m := range e.Mismatches {
+		s[i] = m.String()
+	}
+	return "roundtriptest: " + strings.Join(s, "; ")
+}
+
+// Check decodes the JSON object b into a map configured by opts, encodes
+// it and verifies that b has no duplicate keys and that the encoding has
+// the same content and key order as b at every level. It also verifies
+// that decoding and encoding the encoding again produces the same bytes.
+// It returns an *Error describing the differences, or the error from
+// decoding or encoding.
+func Check(b []byte, opts ...Option) error {
+	first, info, err := roundTrip(b, opts)
+	if err != nil {
+		return err
+	}
+	if len(info.DuplicateKeys) > 0 {
+		return &Error{DuplicateKeys: info.DuplicateKeys}
+	}
+	if ok, mismatches := orderedmap.StrictOrderEqual(b, first); !ok {
+		return &Error{Mismatches: mismatches}
+	}
+	second, _, err := roundTrip(first, opts)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(first, second) {
+		return &Error{Unstable: true}
+	}
+	return nil
+}
+
+// CheckFiles runs Check on each file matching the pattern, as accepted by
+// filepath.Glob, and returns the first error prefixed with the file name.
+func CheckFiles(pattern string, opts ...Option) error {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := Check(b, opts...); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func roundTrip(b []byte, opts []Option) ([]byte, orderedmap.DecodeInfo, error) {
+	o := orderedmap.New()
+	for _, opt := range opts {
+		opt(o)
+	}
+	info, err := o.UnmarshalJSONInfo(b)
+	if err != nil {
+		return nil, info, err
+	}
+	encoded, err := o.MarshalJSON()
+	return encoded, info, err
+}
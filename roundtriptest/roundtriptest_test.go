@@ -0,0 +1,47 @@
+package roundtriptest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iancoleman/orderedmap"
+)
+
+func TestCheck(t *testing.T) {
+	doc := []byte(`{"z":1,"a":{"y":[{"c":null,"b":"<"}],"x":1e3},"m":"é"}`)
+	if err := Check(doc); err != nil {
+		t.Error("Check", err)
+	}
+	lower := func(o *orderedmap.OrderedMap) { o.SetKeyTransform(strings.ToLower) }
+	if err := Check([]byte(`{"B":1,"a":2}`), lower); err == nil {
+		t.Error("Check with key transform did not fail")
+	}
+	dropNulls := func(o *orderedmap.OrderedMap) { o.SetNullMode(orderedmap.NullDrop) }
+	err := Check(doc, dropNulls)
+	var e *Error
+	if !errors.As(err, &e) || len(e.Mismatches) != 2 || e.Mismatches[1].Path != "/a/y/0/c" {
+		t.Error("Check with dropped nulls", err)
+	}
+	if err := Check([]byte(`{"a":1,"b":2,"a":3}`)); err == nil || err.Error() != "roundtriptest: duplicate keys /a" {
+		t.Error("Check with duplicate keys did not fail")
+	}
+	if err := Check([]byte(`[1]`)); err == nil {
+		t.Error("Check with array did not fail")
+	}
+}
+
+func TestCheckFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"b":1,"a":2}`), 0o644)
+	if err := CheckFiles(filepath.Join(dir, "*.json")); err != nil {
+		t.Error("CheckFiles", err)
+	}
+	os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"b":1,"b":2}`), 0o644)
+	err := CheckFiles(filepath.Join(dir, "*.json"))
+	if err == nil || !strings.Contains(err.Error(), "b.json: roundtriptest: ") {
+		t.Error("CheckFiles", err)
+	}
+}
@@ -0,0 +1,44 @@
+package orderedmap
+
+// Union returns a new map with the keys of a in order followed by the keys
+// of b that are not in a, in the order of b. Keys in both maps have the
+// value in a. The result has the settings of a.
+func Union(a, b OrderedMap) OrderedMap {
+	u := a.subMap(func(string) bool { return true })
+	for _, k := range b.keys {
+		ck := u.canonicalKey(k)
+		if _, ok := u.values[ck]; !ok {
+			u.keys = append(u.keys, ck)
+			u.values[ck] = b.values[k]
+		}
+	}
+	return u
+}
+
+// Intersect returns a new map with the keys of a that are also in b, in
+// the order and with the values of a. The result has the settings of a.
+func Intersect(a, b OrderedMap) OrderedMap {
+	inB := canonicalKeys(&a, b)
+	return a.subMap(func(key string) bool {
+		return inB[key]
+	})
+}
+
+// Difference returns a new map with the keys of a that are not in b, in
+// the order and with the values of a. The result has the settings of a.
+func Difference(a, b OrderedMap) OrderedMap {
+	inB := canonicalKeys(&a, b)
+	return a.subMap(func(key string) bool {
+		return !inB[key]
+	})
+}
+
+// canonicalKeys returns the keys of b transformed by the key transform of
+// a, so that they can be compared with the keys of a.
+func canonicalKeys(a *OrderedMap, b OrderedMap) map[string]bool {
+	keys := make(map[string]bool, len(b.keys))
+	for _, k := range b.keys {
+		keys[a.canonicalKey(k)] = true
+	}
+	return keys
+}
@@ -0,0 +1,34 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSetOperations(t *testing.T) {
+	a := mustUnmarshal(t, `{"x":1,"y":2,"z":3}`)
+	b := mustUnmarshal(t, `{"w":0,"z":30,"v":-1,"x":10}`)
+	tests := []struct {
+		name     string
+		result   OrderedMap
+		expected string
+	}{
+		{"Union", Union(a, b), `{"x":1,"y":2,"z":3,"w":0,"v":-1}`},
+		{"Intersect", Intersect(a, b), `{"x":1,"z":3}`},
+		{"Difference", Difference(a, b), `{"y":2}`},
+		{"Difference reversed", Difference(b, a), `{"w":0,"v":-1}`},
+		{"Union with empty", Union(*New(), a), `{"x":1,"y":2,"z":3}`},
+	}
+	for _, test := range tests {
+		got, _ := json.Marshal(test.result)
+		if string(got) != test.expected {
+			t.Error(test.name, string(got))
+		}
+	}
+	// the inputs are not modified
+	u := Union(a, b)
+	u.Set("new", true)
+	if got, _ := json.Marshal(a); string(got) != `{"x":1,"y":2,"z":3}` {
+		t.Error("Union modified its input", string(got))
+	}
+}
@@ -0,0 +1,25 @@
+package orderedmap
+
+// SignedMarshal returns the JSON encoding of the map with a signature added
+// as the last entry under sigKey. The signature is computed by signer over
+// the FormatCanonical encoding of the map without sigKey, so a verifier can
+// recompute the signed bytes by removing sigKey and marshalling the rest with
+// FormatCanonical. The signature is written as a base64 string, as
+// encoding/json does for []byte. The map itself is not modified.
+func (o *OrderedMap) SignedMarshal(signer func(canonical []byte) ([]byte, error), sigKey string) ([]byte, error) {
+	unsigned := o.clone()
+	unsigned.Delete(sigKey)
+	format := unsigned.format
+	unsigned.format = FormatCanonical
+	canonical, err := unsigned.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer(canonical)
+	if err != nil {
+		return nil, err
+	}
+	unsigned.format = format
+	unsigned.Set(sigKey, sig)
+	return unsigned.MarshalJSON()
+}
@@ -0,0 +1,83 @@
+package orderedmap
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSignedMarshal(t *testing.T) {
+	secret := []byte("secret")
+	signer := func(canonical []byte) ([]byte, error) {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(canonical)
+		return mac.Sum(nil), nil
+	}
+	o := New()
+	o.Set("msg", "hello")
+	o.Set("alg", "HS256")
+	o.Set("sig", "stale signature")
+	b, err := o.SignedMarshal(signer, "sig")
+	if err != nil {
+		t.Fatal("SignedMarshal", err)
+	}
+	// the original map is untouched
+	if v, _ := o.Get("sig"); v != "stale signature" {
+		t.Error("SignedMarshal modified the map")
+	}
+	// the signature is the last key
+	signed := New()
+	if err := json.Unmarshal(b, signed); err != nil {
+		t.Fatal("Unmarshal signed", err)
+	}
+	expectedKeys := []string{"msg", "alg", "sig"}
+	k := signed.Keys()
+	for i := range k {
+		if k[i] != expectedKeys[i] {
+			t.Error("Signed key order", i, k[i], "!=", expectedKeys[i])
+		}
+	}
+	// verify by recomputing the canonical bytes
+	sig, _ := signed.Get("sig")
+	signed.Delete("sig")
+	signed.SetFormat(FormatCanonical)
+	canonical, _ := signed.MarshalJSON()
+	if string(canonical) != `{"alg":"HS256","msg":"hello"}` {
+		t.Error("Canonical bytes", string(canonical))
+	}
+	expected, _ := signer(canonical)
+	var got []byte
+	json.Unmarshal([]byte(`"`+sig.(string)+`"`), &got)
+	if !hmac.Equal(got, expected) {
+		t.Error("Signature does not verify")
+	}
+	failing := func([]byte) ([]byte, error) { return nil, errors.New("no key") }
+	if _, err := o.SignedMarshal(failing, "sig"); err == nil {
+		t.Error("Expected signer error")
+	}
+}
+
+func TestSignedMarshalNestedPointerMaps(t *testing.T) {
+	var signed [][]byte
+	signer := func(canonical []byte) ([]byte, error) {
+		signed = append(signed, canonical)
+		return canonical, nil
+	}
+	for _, keys := range [][]string{{"z", "a"}, {"a", "z"}} {
+		inner := New()
+		values := map[string]int{"z": 1, "a": 2}
+		for _, k := range keys {
+			inner.Set(k, values[k])
+		}
+		o := New()
+		o.Set("n", []*OrderedMap{inner})
+		if _, err := o.SignedMarshal(signer, "sig"); err != nil {
+			t.Fatal("SignedMarshal", err)
+		}
+	}
+	if string(signed[0]) != string(signed[1]) || string(signed[0]) != `{"n":[{"a":2,"z":1}]}` {
+		t.Error("Signed bytes depend on nested key order", string(signed[0]), string(signed[1]))
+	}
+}
@@ -0,0 +1,122 @@
+package orderedmap
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortProfile orders keys, the keys of the map at path, in place. path is
+// empty for the outermost map and contains keys and array indices for
+// nested maps.
+type SortProfile func(path []string, keys []string)
+
+// Alphabetical is a SortProfile ordering keys by byte order.
+func Alphabetical(path []string, keys []string) {
+	sort.Strings(keys)
+}
+
+// AlphabeticalCaseInsensitive is a SortProfile ordering keys alphabetically
+// ignoring case. Keys that differ only in case are ordered by byte order.
+func AlphabeticalCaseInsensitive(path []string, keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := strings.ToLower(keys[i]), strings.ToLower(keys[j])
+		if a != b {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// LengthThenAlpha is a SortProfile ordering shorter keys first, and keys of
+// the same length by byte order.
+func LengthThenAlpha(path []string, keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// SchemaOrder returns a SortProfile ordering keys as they are ordered in
+// schema, an example document. Keys that are not in the schema keep their
+// relative order after the keys that are. Arrays in the schema use their
+// first element as the schema for every element of the matching array.
+func SchemaOrder(schema OrderedMap) SortProfile {
+	return func(path []string, keys []string) {
+		var node interface{} = schema
+		for _, segment := range path {
+			switch n := node.(type) {
+			case OrderedMap:
+				node = n.values[segment]
+			case []interface{}:
+				node = nil
+				if len(n) > 0 {
+					node = n[0]
+				}
+			default:
+				return
+			}
+		}
+		m, ok := node.(OrderedMap)
+		if !ok {
+			return
+		}
+		position := make(map[string]int, len(m.keys))
+		for i, k := range m.keys {
+			position[k] = i
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			pi, iok := position[keys[i]]
+			pj, jok := position[keys[j]]
+			if iok && jok {
+				return pi < pj
+			}
+			return iok && !jok
+		})
+	}
+}
+
+// SortWith orders the keys of the map and of every nested map, including
+// maps in arrays, using profile.
+func (o *OrderedMap) SortWith(profile SortProfile) {
+	sortMapWith(nil, o, profile)
+}
+
+func sortMapWith(path []string, o *OrderedMap, profile SortProfile) {
+	profile(path, o.keys)
+	for _, k := range o.keys {
+		sortValueWith(append(path[:len(path):len(path)], k), o.values[k], profile)
+	}
+}
+
+func sortValueWith(path []string, v interface{}, profile SortProfile) {
+	switch v := v.(type) {
+	case OrderedMap:
+		sortMapWith(path, &v, profile)
+	case *OrderedMap:
+		sortMapWith(path, v, profile)
+	case []interface{}:
+		for i, elem := range v {
+			sortValueWith(append(path[:len(path):len(path)], strconv.Itoa(i)), elem, profile)
+		}
+	case []OrderedMap:
+		for i := range v {
+			sortMapWith(append(path[:len(path):len(path)], strconv.Itoa(i)), &v[i], profile)
+		}
+	}
+}
+
+// AdoptOrderFrom orders the keys of the map and of every nested map as they
+// are ordered in reference, a JSON object such as a hand-maintained
+// template, using SchemaOrder. Keys that are not in the reference keep their
+// relative order after the keys that are.
+func (o *OrderedMap) AdoptOrderFrom(reference []byte) error {
+	ref := New()
+	if err := ref.UnmarshalJSON(reference); err != nil {
+		return err
+	}
+	o.SortWith(SchemaOrder(*ref))
+	return nil
+}
@@ -0,0 +1,62 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSortWith(t *testing.T) {
+	s := `{"b":1,"B":2,"aa":{"c":1,"a":2},"a":[{"z":1,"y":2}]}`
+	tests := []struct {
+		profile  SortProfile
+		expected string
+	}{
+		{Alphabetical, `{"B":2,"a":[{"y":2,"z":1}],"aa":{"a":2,"c":1},"b":1}`},
+		{AlphabeticalCaseInsensitive, `{"a":[{"y":2,"z":1}],"aa":{"a":2,"c":1},"B":2,"b":1}`},
+		{LengthThenAlpha, `{"B":2,"a":[{"y":2,"z":1}],"b":1,"aa":{"a":2,"c":1}}`},
+	}
+	for _, test := range tests {
+		o := New()
+		if err := json.Unmarshal([]byte(s), o); err != nil {
+			t.Fatal("Unmarshal", err)
+		}
+		o.SortWith(test.profile)
+		b, _ := json.Marshal(o)
+		if string(b) != test.expected {
+			t.Error("SortWith", string(b), "!=", test.expected)
+		}
+	}
+}
+
+func TestSchemaOrder(t *testing.T) {
+	schema := New()
+	if err := json.Unmarshal([]byte(`{"id":0,"name":"","items":[{"sku":"","qty":0}],"meta":{"created":""}}`), schema); err != nil {
+		t.Fatal("Unmarshal schema", err)
+	}
+	o := New()
+	s := `{"extra":1,"meta":{"updated":"x","created":"y"},"items":[{"qty":1,"note":"n","sku":"a"},{"qty":2,"sku":"b"}],"name":"x","id":7}`
+	if err := json.Unmarshal([]byte(s), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	o.SortWith(SchemaOrder(*schema))
+	b, _ := json.Marshal(o)
+	expected := `{"id":7,"name":"x","items":[{"sku":"a","qty":1,"note":"n"},{"sku":"b","qty":2}],"meta":{"created":"y","updated":"x"},"extra":1}`
+	if string(b) != expected {
+		t.Error("SchemaOrder", string(b), "!=", expected)
+	}
+}
+
+func TestAdoptOrderFrom(t *testing.T) {
+	o := mustUnmarshal(t, `{"version":2,"deps":{"b":"1","a":"2"},"name":"x","local":true}`)
+	if err := o.AdoptOrderFrom([]byte(`{"name":"","version":0,"deps":{"a":"","b":""}}`)); err != nil {
+		t.Fatal("AdoptOrderFrom", err)
+	}
+	b, _ := json.Marshal(o)
+	expected := `{"name":"x","version":2,"deps":{"a":"2","b":"1"},"local":true}`
+	if string(b) != expected {
+		t.Error("AdoptOrderFrom", string(b), "!=", expected)
+	}
+	if err := o.AdoptOrderFrom([]byte(`[1]`)); err == nil {
+		t.Error("AdoptOrderFrom with an array did not fail")
+	}
+}
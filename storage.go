@@ -0,0 +1,177 @@
+package orderedmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Storage is an ordered key-value store that a StoredMap operates over, so
+// that documents too large for memory can be kept in a database or on disk.
+// Implementations must keep keys in insertion order: Store adds a new key
+// after all others and keeps the position of an existing key.
+type Storage interface {
+	// Load returns the value of key and whether it is present.
+	Load(key string) (value interface{}, ok bool, err error)
+	// Store sets the value of key.
+	Store(key string, value interface{}) error
+	// Remove deletes key. Removing a missing key is not an error.
+	Remove(key string) error
+	// Range calls fn for each key and value in order until fn returns
+	// false.
+	Range(fn func(key string, value interface{}) bool) error
+	// Len returns the number of keys.
+	Len() (int, error)
+}
+
+// MemoryStorage is a Storage held in memory by an OrderedMap.
+type MemoryStorage struct {
+	m *OrderedMap
+}
+
+// NewMemoryStorage returns a Storage over o, or over a new map if o is nil.
+// Changes made through the Storage are visible in o.
+func NewMemoryStorage(o *OrderedMap) *MemoryStorage {
+	if o == nil {
+		o = New()
+	}
+	return &MemoryStorage{o}
+}
+
+func (s *MemoryStorage) Load(key string) (interface{}, bool, error) {
+	v, ok := s.m.values[s.m.canonicalKey(key)]
+	return v, ok, nil
+}
+
+func (s *MemoryStorage) Store(key string, value interface{}) error {
+	s.m.Set(key, value)
+	return nil
+}
+
+func (s *MemoryStorage) Remove(key string) error {
+	s.m.Delete(key)
+	return nil
+}
+
+func (s *MemoryStorage) Range(fn func(key string, value interface{}) bool) error {
+	for _, k := range s.m.keys {
+		if !fn(k, s.m.values[k]) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) Len() (int, error) {
+	return len(s.m.keys), nil
+}
+
+// StoredMap is an ordered map whose entries are kept in a Storage. It
+// offers the core of the OrderedMap API, returning the errors of the
+// storage, and marshals by streaming entries from the storage.
+type StoredMap struct {
+	storage    Storage
+	escapeHTML bool
+}
+
+// NewStoredMap returns a map over s.
+func NewStoredMap(s Storage) *StoredMap {
+	return &StoredMap{storage: s, escapeHTML: true}
+}
+
+// SetEscapeHTML sets whether marshalling escapes HTML characters, as for
+// OrderedMap.
+func (m *StoredMap) SetEscapeHTML(on bool) {
+	m.escapeHTML = on
+}
+
+// Get returns the value of key and whether it is present.
+func (m *StoredMap) Get(key string) (interface{}, bool, error) {
+	return m.storage.Load(key)
+}
+
+// Set sets the value of key, adding it to the end if it is new.
+func (m *StoredMap) Set(key string, value interface{}) error {
+	return m.storage.Store(key, value)
+}
+
+// Delete removes key.
+func (m *StoredMap) Delete(key string) error {
+	return m.storage.Remove(key)
+}
+
+// Len returns the number of keys.
+func (m *StoredMap) Len() (int, error) {
+	return m.storage.Len()
+}
+
+// Keys returns the keys in order. For very large maps prefer Range.
+func (m *StoredMap) Keys() ([]string, error) {
+	var keys []string
+	err := m.storage.Range(func(key string, _ interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys, err
+}
+
+// Range calls fn for each key and value in order until fn returns false.
+func (m *StoredMap) Range(fn func(key string, value interface{}) bool) error {
+	return m.storage.Range(fn)
+}
+
+// WriteJSON writes the map to w as a JSON object without holding all of it
+// in memory.
+func (m *StoredMap) WriteJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(m.escapeHTML)
+	// encode writes v to buf, dropping the newline added by Encode
+	encode := func(v interface{}) error {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1)
+		return nil
+	}
+	buf.WriteByte('{')
+	first := true
+	var encErr error
+	err := m.storage.Range(func(key string, value interface{}) bool {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		if encErr = encode(key); encErr != nil {
+			return false
+		}
+		buf.WriteByte(':')
+		if encErr = encode(value); encErr != nil {
+			return false
+		}
+		_, encErr = bw.Write(buf.Bytes())
+		buf.Reset()
+		return encErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	if encErr != nil {
+		return encErr
+	}
+	buf.WriteByte('}')
+	if _, err := bw.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (m *StoredMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := m.WriteJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
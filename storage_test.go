@@ -0,0 +1,63 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStoredMap(t *testing.T) {
+	o := New()
+	m := NewStoredMap(NewMemoryStorage(o))
+	for _, kv := range []Pair{{"b", 1}, {"a", "<x>"}, {"c", Of("z", 1, "y", 2)}, {"b", 3}} {
+		if err := m.Set(kv.key, kv.value); err != nil {
+			t.Fatal("Set", err)
+		}
+	}
+	if err := m.Delete("a"); err != nil {
+		t.Fatal("Delete", err)
+	}
+	m.Set("a", "<x>")
+	if v, ok, err := m.Get("b"); v != 3 || !ok || err != nil {
+		t.Error("Get", v, ok, err)
+	}
+	if keys, _ := m.Keys(); !reflect.DeepEqual(keys, []string{"b", "c", "a"}) {
+		t.Error("Keys", keys)
+	}
+	if n, _ := m.Len(); n != 3 {
+		t.Error("Len", n)
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"b":3,"c":{"z":1,"y":2},"a":"\u003cx\u003e"}` {
+		t.Error("Marshal", string(b))
+	}
+	// the storage writes through to the map
+	if !reflect.DeepEqual(o.Keys(), []string{"b", "c", "a"}) {
+		t.Error("MemoryStorage map", o.Keys())
+	}
+	m.SetEscapeHTML(false)
+	m.Set("d", func() {})
+	if _, err := json.Marshal(m); err == nil {
+		t.Error("Marshal of unsupported value did not fail")
+	}
+}
+
+type failingStorage struct{ *MemoryStorage }
+
+func (s failingStorage) Range(func(string, interface{}) bool) error {
+	return errors.New("range failed")
+}
+
+func TestStoredMapStorageError(t *testing.T) {
+	m := NewStoredMap(failingStorage{NewMemoryStorage(nil)})
+	if _, err := m.Keys(); err == nil || err.Error() != "range failed" {
+		t.Error("Keys", err)
+	}
+	if _, err := m.MarshalJSON(); err == nil {
+		t.Error("MarshalJSON did not fail")
+	}
+}
@@ -0,0 +1,83 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// UnixTimeLayout is a layout for SetTimeLayout that writes times as the
+// number of seconds since the Unix epoch.
+const UnixTimeLayout = "unix"
+
+// TimeDecoder reports whether a value decoded under key is a time, and if
+// so returns it. For array elements, key is the key of the array.
+type TimeDecoder func(key string, v interface{}) (time.Time, bool)
+
+// SetTimeDecoder sets a func that UnmarshalJSON uses to decode values into
+// time.Time, eg RFC3339Times. Values it does not recognise are kept as they
+// are. Use SetTimeLayout to control how times are marshalled. The setting
+// is inherited by nested maps. Pass nil to remove the decoder.
+func (o *OrderedMap) SetTimeDecoder(dec TimeDecoder) {
+	o.timeDecoder = dec
+}
+
+// RFC3339Times is a TimeDecoder that decodes strings in RFC 3339 format,
+// with or without fractional seconds.
+func RFC3339Times(key string, v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
+
+// UnixTimes returns a TimeDecoder that decodes numbers under the given keys
+// as seconds since the Unix epoch, in UTC.
+func UnixTimes(keys ...string) TimeDecoder {
+	return func(key string, v interface{}) (time.Time, bool) {
+		if !containsKey(keys, key) {
+			return time.Time{}, false
+		}
+		var f float64
+		switch n := v.(type) {
+		case float64:
+			f = n
+		case json.Number:
+			var err error
+			if f, err = n.Float64(); err != nil {
+				return time.Time{}, false
+			}
+		default:
+			return time.Time{}, false
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+	}
+}
+
+// decodeTimes replaces the values of o and its nested maps recognised by
+// its time decoder.
+func (o *OrderedMap) decodeTimes() {
+	for _, k := range o.keys {
+		o.values[k] = decodeTime(o.timeDecoder, k, o.values[k])
+	}
+}
+
+func decodeTime(dec TimeDecoder, key string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case OrderedMap:
+		v.decodeTimes()
+		return v
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = decodeTime(dec, key, elem)
+		}
+		return v
+	}
+	if t, ok := dec(key, v); ok {
+		return t
+	}
+	return v
+}
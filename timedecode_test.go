@@ -0,0 +1,44 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSetTimeDecoder(t *testing.T) {
+	o := New()
+	o.SetTimeDecoder(RFC3339Times)
+	input := `{"at":"2020-01-02T03:04:05Z","name":"x","nested":{"list":["2021-06-07T08:09:10.5+02:00","no"]},"n":1}`
+	if err := json.Unmarshal([]byte(input), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if at, ok := o.values["at"].(time.Time); !ok || !at.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Error("RFC3339Times", o.values["at"])
+	}
+	list := o.values["nested"].(OrderedMap).values["list"].([]interface{})
+	if _, ok := list[0].(time.Time); !ok || list[1] != "no" {
+		t.Error("RFC3339Times nested", list)
+	}
+	b, _ := o.MarshalJSON()
+	if string(b) != `{"at":"2020-01-02T03:04:05Z","name":"x","nested":{"list":["2021-06-07T08:09:10.5+02:00","no"]},"n":1}` {
+		t.Error("RFC3339Times marshal", string(b))
+	}
+
+	o = New()
+	o.SetTimeDecoder(UnixTimes("created"))
+	if err := json.Unmarshal([]byte(`{"created":1577934245,"count":1577934245}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	if created, ok := o.values["created"].(time.Time); !ok || !created.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Error("UnixTimes", o.values["created"])
+	}
+	if _, ok := o.values["count"].(float64); !ok {
+		t.Error("UnixTimes decoded another key", o.values["count"])
+	}
+	o.SetTimeLayout(UnixTimeLayout)
+	b, _ = o.MarshalJSON()
+	if string(b) != `{"created":1577934245,"count":1577934245}` {
+		t.Error("UnixTimeLayout", string(b))
+	}
+}
@@ -0,0 +1,201 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// SetTypedArrays sets whether UnmarshalJSON decodes arrays whose elements
+// all have the same type into typed slices: []string, []float64, []bool or
+// []OrderedMap. Empty arrays, arrays of arrays and arrays of mixed types
+// are decoded into []interface{} as usual. The setting is inherited by
+// nested maps.
+func (o *OrderedMap) SetTypedArrays(on bool) {
+	o.typedArrays = on
+}
+
+// typeArrays replaces the homogeneous arrays in v with typed slices.
+func typeArrays(v interface{}) interface{} {
+	switch v := v.(type) {
+	case OrderedMap:
+		for k, elem := range v.values {
+			v.values[k] = typeArrays(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = typeArrays(elem)
+		}
+		return typedSlice(v)
+	}
+	return v
+}
+
+// typedSlice returns s as a typed slice if all of its elements have the
+// same type, otherwise s itself.
+func typedSlice(s []interface{}) interface{} {
+	if len(s) == 0 {
+		return s
+	}
+	switch s[0].(type) {
+	case string:
+		typed := make([]string, len(s))
+		for i, v := range s {
+			str, ok := v.(string)
+			if !ok {
+				return s
+			}
+			typed[i] = str
+		}
+		return typed
+	case float64:
+		typed := make([]float64, len(s))
+		for i, v := range s {
+			f, ok := v.(float64)
+			if !ok {
+				return s
+			}
+			typed[i] = f
+		}
+		return typed
+	case bool:
+		typed := make([]bool, len(s))
+		for i, v := range s {
+			b, ok := v.(bool)
+			if !ok {
+				return s
+			}
+			typed[i] = b
+		}
+		return typed
+	case OrderedMap:
+		typed := make([]OrderedMap, len(s))
+		for i, v := range s {
+			m, ok := v.(OrderedMap)
+			if !ok {
+				return s
+			}
+			typed[i] = m
+		}
+		return typed
+	}
+	return s
+}
+
+// GetString returns the value of key if it is a string.
+func (o *OrderedMap) GetString(key string) (string, bool) {
+	v, _ := o.Get(key)
+	s, ok := v.(string)
+	return s, ok
+}
+
+// GetBool returns the value of key if it is a bool.
+func (o *OrderedMap) GetBool(key string) (bool, bool) {
+	v, _ := o.Get(key)
+	b, ok := v.(bool)
+	return b, ok
+}
+
+// GetInt64 returns the value of key if it is a number that can be
+// represented as an int64 without loss, such as a float64 decoded from a
+// whole JSON number, a json.Number, a *big.Int or any Go integer type in
+// range.
+func (o *OrderedMap) GetInt64(key string) (int64, bool) {
+	v, _ := o.Get(key)
+	return toInt64(v)
+}
+
+// GetFloat64 returns the value of key if it is a number, such as a float64,
+// a json.Number, a big number or any Go integer or float type. Big numbers
+// are rounded to the nearest float64.
+func (o *OrderedMap) GetFloat64(key string) (float64, bool) {
+	v, _ := o.Get(key)
+	return toFloat64(v)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	if i, ok := v.(*big.Int); ok && i != nil {
+		return i.Int64(), i.IsInt64()
+	}
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		v = f
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(u), true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case *big.Int:
+		if n != nil {
+			f, _ := new(big.Float).SetInt(n).Float64()
+			return f, true
+		}
+	case *big.Float:
+		if n != nil {
+			f, _ := n.Float64()
+			return f, true
+		}
+	}
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// GetOrderedMap returns the value of key if it is a nested map, as decoded
+// by UnmarshalJSON, or a non-nil *OrderedMap.
+func (o *OrderedMap) GetOrderedMap(key string) (OrderedMap, bool) {
+	v, _ := o.Get(key)
+	switch m := v.(type) {
+	case OrderedMap:
+		return m, true
+	case *OrderedMap:
+		if m != nil {
+			return *m, true
+		}
+	}
+	return OrderedMap{}, false
+}
+
+// GetSlice returns the value of key if it is a []interface{}, as decoded by
+// UnmarshalJSON.
+func (o *OrderedMap) GetSlice(key string) ([]interface{}, bool) {
+	v, _ := o.Get(key)
+	s, ok := v.([]interface{})
+	return s, ok
+}
@@ -0,0 +1,154 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSetTypedArrays(t *testing.T) {
+	s := `{
+  "strings": ["a", "b"],
+  "numbers": [1, 2.5],
+  "bools": [true, false],
+  "maps": [{"b": 1, "a": 2}, {"c": ["x"]}],
+  "mixed": [1, "a"],
+  "nested": [[1], [2]],
+  "empty": [],
+  "object": {"inner": ["y"]}
+}`
+	o := New()
+	o.SetTypedArrays(true)
+	if err := json.Unmarshal([]byte(s), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	expected := map[string]interface{}{
+		"strings": []string{"a", "b"},
+		"numbers": []float64{1, 2.5},
+		"bools":   []bool{true, false},
+		"mixed":   []interface{}{float64(1), "a"},
+		"nested":  []interface{}{[]float64{1}, []float64{2}},
+		"empty":   []interface{}{},
+	}
+	for k, e := range expected {
+		v, _ := o.Get(k)
+		if !reflect.DeepEqual(v, e) {
+			t.Errorf("Typed array %s: %#v != %#v", k, v, e)
+		}
+	}
+	v, _ := o.Get("maps")
+	maps, ok := v.([]OrderedMap)
+	if !ok {
+		t.Fatalf("Array of maps has type %T", v)
+	}
+	if maps[0].Keys()[0] != "b" {
+		t.Error("Typed array of maps lost key order")
+	}
+	if c, _ := maps[1].Get("c"); !reflect.DeepEqual(c, []string{"x"}) {
+		t.Errorf("Array in map in typed array: %#v", c)
+	}
+	v, _ = o.Get("object")
+	object := v.(OrderedMap)
+	if inner, _ := object.Get("inner"); !reflect.DeepEqual(inner, []string{"y"}) {
+		t.Errorf("Array in nested map: %#v", inner)
+	}
+	// typed arrays marshal back to the same json
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal("Marshal", err)
+	}
+	if string(b) != `{"strings":["a","b"],"numbers":[1,2.5],"bools":[true,false],"maps":[{"b":1,"a":2},{"c":["x"]}],"mixed":[1,"a"],"nested":[[1],[2]],"empty":[],"object":{"inner":["y"]}}` {
+		t.Error("Marshal typed arrays", string(b))
+	}
+}
+
+func TestTypedGetters(t *testing.T) {
+	o := New()
+	if err := json.Unmarshal([]byte(`{"s":"x","b":true,"i":42,"f":1.5,"big":1e300,"neg":-3}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	o.Set("int", 7)
+	o.Set("uint8", uint8(8))
+	o.Set("number", json.Number("9007199254740993"))
+	o.Set("maxuint", uint64(math.MaxUint64))
+	if s, ok := o.GetString("s"); !ok || s != "x" {
+		t.Error("GetString", s, ok)
+	}
+	if _, ok := o.GetString("i"); ok {
+		t.Error("GetString of a number")
+	}
+	if b, ok := o.GetBool("b"); !ok || !b {
+		t.Error("GetBool", b, ok)
+	}
+	if _, ok := o.GetBool("missing"); ok {
+		t.Error("GetBool of a missing key")
+	}
+	int64Tests := []struct {
+		key      string
+		expected int64
+		ok       bool
+	}{
+		{"i", 42, true},
+		{"neg", -3, true},
+		{"int", 7, true},
+		{"uint8", 8, true},
+		{"number", 9007199254740993, true},
+		{"f", 0, false},
+		{"big", 0, false},
+		{"maxuint", 0, false},
+		{"s", 0, false},
+	}
+	for _, test := range int64Tests {
+		if i, ok := o.GetInt64(test.key); i != test.expected || ok != test.ok {
+			t.Error("GetInt64", test.key, i, ok)
+		}
+	}
+	float64Tests := []struct {
+		key      string
+		expected float64
+		ok       bool
+	}{
+		{"f", 1.5, true},
+		{"int", 7, true},
+		{"number", 9007199254740992, true},
+		{"s", 0, false},
+	}
+	for _, test := range float64Tests {
+		if f, ok := o.GetFloat64(test.key); f != test.expected || ok != test.ok {
+			t.Error("GetFloat64", test.key, f, ok)
+		}
+	}
+}
+
+func TestGetOrderedMapGetSlice(t *testing.T) {
+	o := New()
+	if err := json.Unmarshal([]byte(`{"m":{"b":1,"a":2},"s":[1,"x"],"n":1}`), o); err != nil {
+		t.Fatal("Unmarshal", err)
+	}
+	m, ok := o.GetOrderedMap("m")
+	if !ok || m.Keys()[0] != "b" {
+		t.Error("GetOrderedMap", m, ok)
+	}
+	if _, ok := o.GetOrderedMap("n"); ok {
+		t.Error("GetOrderedMap of a number")
+	}
+	p := New()
+	p.Set("x", 1)
+	o.Set("pointer", p)
+	if m, ok := o.GetOrderedMap("pointer"); !ok || m.Keys()[0] != "x" {
+		t.Error("GetOrderedMap of pointer", m, ok)
+	}
+	var nilMap *OrderedMap
+	o.Set("nil", nilMap)
+	if _, ok := o.GetOrderedMap("nil"); ok {
+		t.Error("GetOrderedMap of nil pointer")
+	}
+	s, ok := o.GetSlice("s")
+	if !ok || len(s) != 2 || s[1] != "x" {
+		t.Error("GetSlice", s, ok)
+	}
+	if _, ok := o.GetSlice("m"); ok {
+		t.Error("GetSlice of a map")
+	}
+}
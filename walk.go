@@ -0,0 +1,185 @@
+package orderedmap
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrStopWalk is returned by a Walk function to stop walking without
+// Walk returning an error.
+var ErrStopWalk = errors.New("orderedmap: stop walk")
+
+// Walk calls fn for every value in the map, including the values of nested
+// maps and the elements of arrays, in document order. A map or array is
+// visited before its contents. The path holds the keys and array indices
+// leading to the value and must not be modified by fn. Walking stops at the
+// first error fn returns, which Walk returns unless it is ErrStopWalk.
+func (o *OrderedMap) Walk(fn func(path []string, value interface{}) error) (err error) {
+	if o == nil {
+		return nil
+	}
+	if o.guard != nil {
+		g := o.guard
+		start := g.begin()
+		defer func() {
+			if keys := o.addedOrRemoved(g.end(start)); len(keys) > 0 && err == nil {
+				err = fmt.Errorf("orderedmap: keys %q added or removed during Walk", keys)
+			}
+		}()
+	}
+	err = walkMap(o, nil, fn)
+	if err == ErrStopWalk {
+		return nil
+	}
+	return err
+}
+
+func walkMap(o *OrderedMap, path []string, fn func([]string, interface{}) error) error {
+	for _, k := range o.keys {
+		if err := walkValue(o.values[k], append(path[:len(path):len(path)], k), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkValue(v interface{}, path []string, fn func([]string, interface{}) error) error {
+	if err := fn(path, v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case OrderedMap:
+		return walkMap(&v, path, fn)
+	case *OrderedMap:
+		if v != nil {
+			return walkMap(v, path, fn)
+		}
+	case []interface{}:
+		for i, e := range v {
+			if err := walkValue(e, append(path[:len(path):len(path)], strconv.Itoa(i)), fn); err != nil {
+				return err
+			}
+		}
+	case []OrderedMap:
+		for i := range v {
+			if err := walkValue(v[i], append(path[:len(path):len(path)], strconv.Itoa(i)), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WalkArrays calls fn for every array in the map, including arrays nested
+// in other arrays and maps, and replaces each array with the one fn
+// returns. Inner arrays are visited before the arrays containing them, so fn
+// sees their rewritten elements. The path holds the keys and array indices
+// leading to the array. Walking stops at the first error fn returns.
+func (o *OrderedMap) WalkArrays(fn func(path []string, arr []interface{}) ([]interface{}, error)) error {
+	return walkArraysMap(o, nil, fn)
+}
+
+func walkArraysMap(o *OrderedMap, path []string, fn func([]string, []interface{}) ([]interface{}, error)) error {
+	for _, k := range o.keys {
+		v, err := walkArraysValue(o.values[k], append(path[:len(path):len(path)], k), fn)
+		if err != nil {
+			return err
+		}
+		if arr, ok := v.([]interface{}); ok {
+			o.Set(k, arr)
+		}
+	}
+	return nil
+}
+
+// walkArraysValue walks v and returns the rewritten value if v is an
+// array, otherwise v.
+func walkArraysValue(v interface{}, path []string, fn func([]string, []interface{}) ([]interface{}, error)) (interface{}, error) {
+	switch v := v.(type) {
+	case OrderedMap:
+		return v, walkArraysMap(&v, path, fn)
+	case *OrderedMap:
+		if v == nil {
+			return v, nil
+		}
+		return v, walkArraysMap(v, path, fn)
+	case []interface{}:
+		for i, e := range v {
+			e, err := walkArraysValue(e, append(path[:len(path):len(path)], strconv.Itoa(i)), fn)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = e
+		}
+		return fn(path, v)
+	}
+	return v, nil
+}
+
+// PathValue is a value found in a map and the path leading to it.
+type PathValue struct {
+	// Path holds the keys and array indices leading to the value.
+	Path  []string
+	Value interface{}
+}
+
+// FindAll returns every value of key in the map and its nested maps,
+// including maps in arrays, in document order.
+func (o *OrderedMap) FindAll(key string) []PathValue {
+	var found []PathValue
+	var parents []interface{}
+	o.Walk(func(path []string, value interface{}) error {
+		// parents holds the values on the path to value
+		parents = append(parents[:len(path)-1], value)
+		if path[len(path)-1] != key {
+			return nil
+		}
+		if len(path) == 1 {
+			found = append(found, PathValue{path, value})
+		} else if _, ok := asMap(parents[len(path)-2]); ok {
+			found = append(found, PathValue{path, value})
+		}
+		return nil
+	})
+	return found
+}
+
+// TransformValues replaces every value in the map, including the values of
+// nested maps and the elements of arrays, with the result of fn, keeping
+// the order of keys. fn is called in document order with the path leading
+// to the value, as for Walk, and is called for maps and arrays before their
+// contents, so that it can replace them as a whole. The contents of the
+// value fn returns are transformed in turn, so fn should return maps and
+// arrays it does not replace unchanged.
+func (o *OrderedMap) TransformValues(fn func(path []string, v interface{}) interface{}) {
+	transformMap(o, nil, fn)
+}
+
+func transformMap(o *OrderedMap, path []string, fn func([]string, interface{}) interface{}) {
+	for _, k := range o.keys {
+		o.Set(k, transformValue(o.values[k], append(path[:len(path):len(path)], k), fn))
+	}
+}
+
+func transformValue(v interface{}, path []string, fn func([]string, interface{}) interface{}) interface{} {
+	v = fn(path, v)
+	switch v := v.(type) {
+	case OrderedMap:
+		transformMap(&v, path, fn)
+		return v
+	case *OrderedMap:
+		if v != nil {
+			transformMap(v, path, fn)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = transformValue(e, append(path[:len(path):len(path)], strconv.Itoa(i)), fn)
+		}
+	case []OrderedMap:
+		for i := range v {
+			transformMap(&v[i], append(path[:len(path):len(path)], strconv.Itoa(i)), fn)
+		}
+	}
+	return v
+}
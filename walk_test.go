@@ -0,0 +1,131 @@
+package orderedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWalkArrays(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":[3,1,[2,2]],"b":{"c":["x","x","y"]},"d":1}`)
+	var paths []string
+	err := o.WalkArrays(func(path []string, arr []interface{}) ([]interface{}, error) {
+		paths = append(paths, strings.Join(path, "."))
+		// dedupe
+		var out []interface{}
+		seen := map[string]bool{}
+		for _, v := range arr {
+			b, _ := json.Marshal(v)
+			if !seen[string(b)] {
+				seen[string(b)] = true
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	})
+	if err != nil {
+		t.Fatal("WalkArrays", err)
+	}
+	b, _ := json.Marshal(o)
+	expected := `{"a":[3,1,[2]],"b":{"c":["x","y"]},"d":1}`
+	if string(b) != expected {
+		t.Error("WalkArrays", string(b), "!=", expected)
+	}
+	if !reflect.DeepEqual(paths, []string{"a.2", "a", "b.c"}) {
+		t.Error("WalkArrays paths", paths)
+	}
+	errStop := errors.New("stop")
+	err = o.WalkArrays(func(path []string, arr []interface{}) ([]interface{}, error) {
+		return nil, errStop
+	})
+	if err != errStop {
+		t.Error("WalkArrays error", err)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":[1,{"b":2}],"c":{"d":null},"e":"f"}`)
+	var visited []string
+	err := o.Walk(func(path []string, value interface{}) error {
+		b, _ := json.Marshal(value)
+		visited = append(visited, strings.Join(path, ".")+"="+string(b))
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Walk", err)
+	}
+	expected := []string{
+		`a=[1,{"b":2}]`,
+		`a.0=1`,
+		`a.1={"b":2}`,
+		`a.1.b=2`,
+		`c={"d":null}`,
+		`c.d=null`,
+		`e="f"`,
+	}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Error("Walk", visited)
+	}
+	visited = nil
+	err = o.Walk(func(path []string, value interface{}) error {
+		visited = append(visited, strings.Join(path, "."))
+		if len(path) == 3 {
+			return ErrStopWalk
+		}
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(visited, []string{"a", "a.0", "a.1", "a.1.b"}) {
+		t.Error("Walk with ErrStopWalk", visited, err)
+	}
+	errStop := errors.New("stop")
+	if err := o.Walk(func([]string, interface{}) error { return errStop }); err != errStop {
+		t.Error("Walk error", err)
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":{"id":1,"x":[{"id":2},{"y":{"id":3}}]},"id":4,"list":["id"],"0":[5]}`)
+	var found []string
+	for _, pv := range o.FindAll("id") {
+		found = append(found, fmt.Sprint(strings.Join(pv.Path, "."), "=", pv.Value))
+	}
+	expected := []string{"a.id=1", "a.x.0.id=2", "a.x.1.y.id=3", "id=4"}
+	if !reflect.DeepEqual(found, expected) {
+		t.Error("FindAll", found)
+	}
+	// array indices are not keys
+	if found := o.FindAll("0"); len(found) != 1 || found[0].Path[0] != "0" {
+		t.Error("FindAll array index", found)
+	}
+}
+
+func TestTransformValues(t *testing.T) {
+	o := mustUnmarshal(t, `{"user":{"name":"a","password":"p","keys":{"k":1}},"tokens":[{"secret":"s"}],"n":2}`)
+	var paths []string
+	o.TransformValues(func(path []string, v interface{}) interface{} {
+		paths = append(paths, strings.Join(path, "."))
+		switch path[len(path)-1] {
+		case "password", "secret", "keys":
+			return "***"
+		}
+		if f, ok := v.(float64); ok {
+			return int(f)
+		}
+		return v
+	})
+	b, _ := json.Marshal(o)
+	expected := `{"user":{"name":"a","password":"***","keys":"***"},"tokens":[{"secret":"***"}],"n":2}`
+	if string(b) != expected {
+		t.Error("TransformValues", string(b))
+	}
+	if n, _ := o.Get("n"); n != 2 {
+		t.Error("TransformValues number", n)
+	}
+	expectedPaths := []string{"user", "user.name", "user.password", "user.keys", "tokens", "tokens.0", "tokens.0.secret", "n"}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Error("TransformValues paths", paths)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build go1.23
+// +build go1.23
+
+package orderedmap
+
+import "iter"
+
+// Windows returns an iterator over the windows of n consecutive entries of
+// the map, in order, eg for n = 2 the first and second entries, then the
+// second and third. A map with fewer than n entries has no windows, and n
+// must be positive. Each window is a new slice, so it can be kept.
+func (o *OrderedMap) Windows(n int) iter.Seq[[]Pair] {
+	if n <= 0 {
+		panic("orderedmap: Windows size must be positive")
+	}
+	return func(yield func([]Pair) bool) {
+		if o == nil {
+			return
+		}
+		for i := 0; i+n <= len(o.keys); i++ {
+			window := make([]Pair, n)
+			for j, key := range o.keys[i : i+n] {
+				window[j] = Pair{key, o.values[key]}
+			}
+			if !yield(window) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+//go:build go1.23
+// +build go1.23
+
+package orderedmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWindows(t *testing.T) {
+	o := mustUnmarshal(t, `{"a":1,"a_sum":"x","b":2,"c":3}`)
+	var windows [][]string
+	for w := range o.Windows(2) {
+		windows = append(windows, []string{w[0].Key(), w[1].Key()})
+	}
+	expected := [][]string{{"a", "a_sum"}, {"a_sum", "b"}, {"b", "c"}}
+	if !reflect.DeepEqual(windows, expected) {
+		t.Error("Windows", windows)
+	}
+	n := 0
+	for range o.Windows(1) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Error("Windows with break", n)
+	}
+	for range o.Windows(5) {
+		t.Error("Windows larger than the map")
+	}
+	var nilMap *OrderedMap
+	for range nilMap.Windows(1) {
+		t.Error("Windows over a nil map")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Windows(0) did not panic")
+		}
+	}()
+	o.Windows(0)
+}